@@ -6,6 +6,7 @@ import (
 
 	"api/internal/hooks"
 	"api/internal/jobs"
+	"api/internal/routes"
 	_ "api/migrations"
 
 	"github.com/joho/godotenv"
@@ -61,9 +62,21 @@ func main() {
 	})
 
 	hooks.ConfigureHooks(app)
+	routes.ConfigureRoutes(app)
 
-	app.Cron().MustAdd("analyze-sermons", "* * * * *", func() {
-		jobs.SermonAnalysisJob(app)
+	pool := jobs.NewPool(app, jobs.PoolConfigFromEnv())
+
+	// anything left pending by a previous process (crash, deploy, auto-stop) goes back in the queue
+	// before the workers start picking up jobs
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		jobs.ReclaimStartupLeases(se.App)
+		pool.Start()
+		return se.Next()
+	})
+
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		pool.Stop()
+		return e.Next()
 	})
 
 	if err := app.Start(); err != nil {
@@ -1,145 +1,63 @@
 package ai
 
 import (
+	"api/internal/audio"
 	"api/internal/models"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"log/slog"
-	"mime"
-	"net/http"
-	"os"
-	"path"
-	"strings"
-
-	"google.golang.org/genai"
 )
 
 //go:embed prompt.txt
 var prompt string
 
+// Analyzer works on a sermon in two phases, so the analysis can be re-run on a stored transcript
 type Analyzer interface {
-	AnalyzeSermon(job models.SermonAnalysisJob) (AnalysisResult, error)
+	// Transcribe turns the sermon's recording into a timestamped transcript
+	Transcribe(job models.SermonAnalysisJob, source AudioSource) (Transcript, error)
+	// AnalyzeTranscript takes notes on the transcript and comes up with discussion questions
+	AnalyzeTranscript(job models.SermonAnalysisJob, transcript Transcript) (AnalysisResult, error)
 }
 
+// AudioSource fetches a job's recording to a local file. Analyzers only call it once they
+// actually need the audio, and call cleanup when they're done with the file.
+type AudioSource func(ctx context.Context) (path string, cleanup func(), err error)
+
 type AnalysisResult struct {
 	Summary   string                  `json:"summary"`
-	Questions []models.SermonQuestion `json:"questions"`
+	Topics    []string                `json:"topics"`
 	Details   []models.SermonDetail   `json:"notes"`
+	Questions []models.SermonQuestion `json:"questions"`
 }
 
+// NewAnalyzer creates an Analyzer using the provider configured in the environment
 func NewAnalyzer(job models.SermonAnalysisJob, logger *slog.Logger) (Analyzer, error) {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return &sermonAnalyzer{
-		ctx:    ctx,
-		job:    job,
-		client: client,
-		logger: logger,
-	}, nil
+	return NewAnalyzerWithConfig(ConfigFromEnv(), job, logger)
 }
 
-type sermonAnalyzer struct {
-	ctx    context.Context
-	job    models.SermonAnalysisJob
-	client *genai.Client
-	logger *slog.Logger
-}
-
-func (a *sermonAnalyzer) AnalyzeSermon(job models.SermonAnalysisJob) (AnalysisResult, error) {
-	if job.AudioURL == "" {
-		return AnalysisResult{}, errors.New("audio url is required")
-	}
-
-	a.logger.Info("Downloading sermon audio", "url", job.AudioURL, "job_id", job.Id)
-	tmpFile, err := downloadFile(job.AudioURL, job.Id)
-	if err != nil {
-		return AnalysisResult{}, err
-	}
-	defer tmpFile.Close()
-
-	mimeType := mime.TypeByExtension(path.Ext(tmpFile.Name()))
-	// handle edge cases where mime type is not detected
-	if mimeType == "" && strings.HasSuffix(tmpFile.Name(), ".mp3") {
-		mimeType = "audio/mpeg"
-	}
-	a.logger.Info("Audio downloaded", "job_id", job.Id, "file", tmpFile.Name(), "mime_type", mimeType)
-
-	file, err := a.client.Files.UploadFromPath(a.ctx, tmpFile.Name(), &genai.UploadFileConfig{
-		MIMEType: mimeType,
-	})
+// NewAnalyzerWithConfig creates an Analyzer using the provider named in cfg
+func NewAnalyzerWithConfig(cfg Config, job models.SermonAnalysisJob, logger *slog.Logger) (Analyzer, error) {
+	provider, err := lookupProvider(cfg.Provider)
 	if err != nil {
-		return AnalysisResult{}, err
-	}
-	defer a.client.Files.Delete(a.ctx, file.Name, nil)
-
-	parts := []*genai.Part{
-		genai.NewPartFromText(prompt),
-		genai.NewPartFromURI(file.URI, file.MIMEType),
-	}
-	contents := []*genai.Content{
-		genai.NewContentFromParts(parts, genai.RoleUser),
-	}
-
-	a.logger.Info("Uploading audio to Gemini", "job_id", job.Id)
-	resp, err := a.client.Models.GenerateContent(a.ctx, "gemini-2.5-flash", contents, &genai.GenerateContentConfig{
-		MaxOutputTokens: 65536,
-	})
-	if err != nil {
-		return AnalysisResult{}, err
-	}
-
-	a.logger.Info("Gemini response", "job_id", job.Id, "response", resp.Text())
-	text := strings.ReplaceAll(resp.Text(), "```json", "")
-	text = strings.ReplaceAll(text, "```", "")
-
-	var result AnalysisResult
-	err = json.Unmarshal([]byte(text), &result)
-	if err != nil {
-		return AnalysisResult{}, errors.Join(err, errors.New("failed to unmarshal response: "+resp.Text()))
+		return nil, err
 	}
 
-	return result, nil
+	return provider(cfg, job, logger.With("provider", cfg.Provider))
 }
 
-// downloadFile downloads a file from a url to a temp file
-// NOTE: returned file must be closed by the caller!
-func downloadFile(url string, jobId string) (*os.File, error) {
-	tmpDir := os.TempDir()
-
-	ext := path.Ext(strings.Split(url, "?")[0])
-	tempFile := path.Join(tmpDir, fmt.Sprintf("sermon-%s.%s", jobId, ext))
-
-	out, err := os.Create(tempFile)
-	if err != nil {
-		return nil, err
-	}
-	defer out.Close()
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// URLAudio is an AudioSource that downloads the recording from a url
+func URLAudio(url string) AudioSource {
+	return func(ctx context.Context) (string, func(), error) {
+		if url == "" {
+			return "", nil, errors.New("audio url is required")
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad status: %s", resp.Status)
-	}
+		file, err := audio.Download(ctx, audio.DownloadConfigFromEnv(), url)
+		if err != nil {
+			return "", nil, err
+		}
 
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return nil, err
+		return file.Path, func() { file.Remove() }, nil
 	}
-
-	return out, nil
 }
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"api/internal/audio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+
+	"google.golang.org/genai"
+)
+
+// ErrMalformedResponse means the model answered but not with something we could use.
+// Models aren't deterministic, so asking again is worth it.
+var ErrMalformedResponse = errors.New("malformed model response")
+
+// StatusError is returned when an http endpoint answers with a non 2xx status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("bad status: %s", e.Status)
+	}
+	return fmt.Sprintf("bad status: %s: %s", e.Status, e.Body)
+}
+
+func newStatusError(resp *http.Response, body string) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       body,
+	}
+}
+
+// IsRetryable reports whether an analysis error is transient (timeouts, dropped connections,
+// rate limits and server errors) and worth retrying. Anything else, like a 404 for the audio
+// or an invalid url, is permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrMalformedResponse) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, os.ErrDeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return retryableStatus(statusErr.StatusCode)
+	}
+
+	var downloadErr *audio.StatusError
+	if errors.As(err, &downloadErr) {
+		return retryableStatus(downloadErr.StatusCode)
+	}
+
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.Code)
+	}
+	var apiErrPtr *genai.APIError
+	if errors.As(err, &apiErrPtr) {
+		return retryableStatus(apiErrPtr.Code)
+	}
+
+	return false
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500
+}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"api/internal/models"
+	"log/slog"
+	"sync"
+)
+
+func init() {
+	RegisterProvider("fake", DefaultFakeProvider)
+}
+
+// DefaultFakeProvider always returns FakeResult, handy for running the pipeline locally without a model
+func DefaultFakeProvider(cfg Config, job models.SermonAnalysisJob, logger *slog.Logger) (Analyzer, error) {
+	return NewFakeAnalyzer(), nil
+}
+
+// FakeResponse is a single scripted answer from a FakeAnalyzer
+type FakeResponse struct {
+	Result AnalysisResult
+	Err    error
+}
+
+// FakeAnalyzer is a deterministic Analyzer that replays scripted responses in order,
+// falling back to FakeResult once the script runs out. It is safe for concurrent use.
+type FakeAnalyzer struct {
+	// OnCall, if set, runs at the start of every AnalyzeTranscript call
+	OnCall func(job models.SermonAnalysisJob)
+
+	// TranscribeErr, if set, is returned by Transcribe instead of FakeTranscript
+	TranscribeErr error
+
+	mu             sync.Mutex
+	script         []FakeResponse
+	calls          []models.SermonAnalysisJob
+	transcriptions []models.SermonAnalysisJob
+}
+
+func NewFakeAnalyzer(responses ...FakeResponse) *FakeAnalyzer {
+	return &FakeAnalyzer{script: responses}
+}
+
+// Push appends responses to the script
+func (f *FakeAnalyzer) Push(responses ...FakeResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.script = append(f.script, responses...)
+}
+
+// Calls returns every job the analyzer has been asked to analyze, in order
+func (f *FakeAnalyzer) Calls() []models.SermonAnalysisJob {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]models.SermonAnalysisJob(nil), f.calls...)
+}
+
+// Transcriptions returns every job the analyzer has been asked to transcribe, in order
+func (f *FakeAnalyzer) Transcriptions() []models.SermonAnalysisJob {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]models.SermonAnalysisJob(nil), f.transcriptions...)
+}
+
+// Provider returns a Provider that always hands out this analyzer
+func (f *FakeAnalyzer) Provider() Provider {
+	return func(cfg Config, job models.SermonAnalysisJob, logger *slog.Logger) (Analyzer, error) {
+		return f, nil
+	}
+}
+
+// Transcribe never fetches the audio, so jobs don't need a reachable recording
+func (f *FakeAnalyzer) Transcribe(job models.SermonAnalysisJob, source AudioSource) (Transcript, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.transcriptions = append(f.transcriptions, job)
+	if f.TranscribeErr != nil {
+		return Transcript{}, f.TranscribeErr
+	}
+	return FakeTranscript(), nil
+}
+
+func (f *FakeAnalyzer) AnalyzeTranscript(job models.SermonAnalysisJob, transcript Transcript) (AnalysisResult, error) {
+	if f.OnCall != nil {
+		f.OnCall(job)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, job)
+	if len(f.script) == 0 {
+		return FakeResult(), nil
+	}
+
+	next := f.script[0]
+	f.script = f.script[1:]
+	return next.Result, next.Err
+}
+
+// FakeTranscript is the canned transcript returned by a FakeAnalyzer
+func FakeTranscript() Transcript {
+	return Transcript{
+		Segments: []models.TranscriptSegment{
+			{StartMs: 0, EndMs: 4200, Text: "Turn with me to Matthew chapter five."},
+			{StartMs: 4200, EndMs: 9800, Text: "Blessed are the poor in spirit, for theirs is the kingdom of heaven."},
+			{StartMs: 9800, EndMs: 15100, Text: "Blessed are those who mourn, for they shall be comforted."},
+		},
+	}
+}
+
+// FakeResult is the canned analysis returned by a FakeAnalyzer with nothing scripted
+func FakeResult() AnalysisResult {
+	return AnalysisResult{
+		Summary: "A sermon on the Beatitudes and what it means to be blessed.",
+		Topics:  []string{"beatitudes", "blessing", "humility", "grief"},
+		Details: []models.SermonDetail{
+			{
+				Title:          "The poor in spirit",
+				Description:    "Blessing starts with recognizing our need for God.",
+				KeyVerse:       "Matt 5:3",
+				RelevantVerses: "Isa 57:15|Luke 18:13",
+			},
+			{
+				Title:          "Those who mourn",
+				Description:    "God meets us in grief and promises comfort.",
+				KeyVerse:       "Matt 5:4",
+				RelevantVerses: "Ps 34:18|Rev 21:4",
+			},
+		},
+		Questions: []models.SermonQuestion{
+			{
+				Title:       "What does it look like to be poor in spirit today?",
+				Description: "(Leader note) Steer the group towards everyday examples.",
+			},
+			{
+				Title:       "Where have you experienced comfort while mourning?",
+				Description: "",
+			},
+		},
+	}
+}
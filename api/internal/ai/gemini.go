@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"api/internal/audio"
+	"api/internal/models"
+	"context"
+	"log/slog"
+
+	"google.golang.org/genai"
+)
+
+func init() {
+	RegisterProvider("gemini", newGeminiAnalyzer)
+}
+
+func newGeminiAnalyzer(cfg Config, job models.SermonAnalysisJob, logger *slog.Logger) (Analyzer, error) {
+	cfg = cfg.withDefaults("gemini-2.5-flash", "", "GEMINI_API_KEY")
+
+	ctx := context.Background()
+	clientConfig := &genai.ClientConfig{
+		APIKey:  cfg.APIKey,
+		Backend: genai.BackendGeminiAPI,
+	}
+	if cfg.BaseURL != "" {
+		clientConfig.HTTPOptions.BaseURL = cfg.BaseURL
+	}
+
+	client, err := genai.NewClient(ctx, clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	gemini := &geminiGenerator{
+		model:  cfg.Model,
+		client: client,
+		logger: logger,
+	}
+	return newPipeline(ctx, gemini, logger), nil
+}
+
+type geminiGenerator struct {
+	model  string
+	client *genai.Client
+	logger *slog.Logger
+}
+
+func (g *geminiGenerator) generate(ctx context.Context, prompt string, audioPath string, schema *jsonSchema) (string, error) {
+	parts := []*genai.Part{genai.NewPartFromText(prompt)}
+
+	if audioPath != "" {
+		mimeType := audio.MIMEType(audioPath)
+		g.logger.Info("Uploading audio to Gemini", "file", audioPath, "mime_type", mimeType)
+
+		file, err := g.client.Files.UploadFromPath(ctx, audioPath, &genai.UploadFileConfig{
+			MIMEType: mimeType,
+		})
+		if err != nil {
+			return "", err
+		}
+		defer g.client.Files.Delete(ctx, file.Name, nil)
+
+		parts = append(parts, genai.NewPartFromURI(file.URI, file.MIMEType))
+	}
+
+	contents := []*genai.Content{
+		genai.NewContentFromParts(parts, genai.RoleUser),
+	}
+
+	g.logger.Info("Sending prompt to Gemini", "model", g.model)
+	resp, err := g.client.Models.GenerateContent(ctx, g.model, contents, &genai.GenerateContentConfig{
+		MaxOutputTokens:  65536,
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   schema.genaiSchema(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	g.logger.Info("Gemini response", "response", resp.Text())
+	return resp.Text(), nil
+}
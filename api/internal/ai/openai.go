@@ -0,0 +1,167 @@
+package ai
+
+import (
+	"api/internal/models"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterProvider("openai", openAICompatibleProvider("gpt-4o-audio-preview", "https://api.openai.com/v1", "OPENAI_API_KEY"))
+	// local servers speak the same chat completions api, they only differ in where they listen
+	RegisterProvider("ollama", openAICompatibleProvider("", "http://localhost:11434/v1", ""))
+	RegisterProvider("llamacpp", openAICompatibleProvider("", "http://localhost:8080/v1", ""))
+}
+
+// openAICompatibleProvider returns a Provider for any server implementing the OpenAI chat completions api
+func openAICompatibleProvider(defaultModel, defaultBaseURL, apiKeyEnv string) Provider {
+	return func(cfg Config, job models.SermonAnalysisJob, logger *slog.Logger) (Analyzer, error) {
+		cfg = cfg.withDefaults(defaultModel, defaultBaseURL, apiKeyEnv)
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("AI_MODEL is required for the %s provider", cfg.Provider)
+		}
+
+		openAI := &openAIGenerator{
+			model:   cfg.Model,
+			baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+			apiKey:  cfg.APIKey,
+			client:  &http.Client{Timeout: 15 * time.Minute},
+			logger:  logger,
+		}
+		return newPipeline(context.Background(), openAI, logger), nil
+	}
+}
+
+type openAIGenerator struct {
+	model   string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	logger  *slog.Logger
+}
+
+type chatCompletionRequest struct {
+	Model          string              `json:"model"`
+	Messages       []chatMessage       `json:"messages"`
+	MaxTokens      int                 `json:"max_tokens,omitempty"`
+	ResponseFormat *chatResponseFormat `json:"response_format,omitempty"`
+}
+
+// chatResponseFormat constrains the completion to a JSON schema (structured outputs)
+type chatResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *chatJSONSchema `json:"json_schema,omitempty"`
+}
+
+type chatJSONSchema struct {
+	Name   string      `json:"name"`
+	Schema *jsonSchema `json:"schema"`
+}
+
+type chatMessage struct {
+	Role    string            `json:"role"`
+	Content []chatContentPart `json:"content"`
+}
+
+type chatContentPart struct {
+	Type       string          `json:"type"`
+	Text       string          `json:"text,omitempty"`
+	InputAudio *chatInputAudio `json:"input_audio,omitempty"`
+}
+
+type chatInputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+func (g *openAIGenerator) generate(ctx context.Context, prompt string, audioPath string, schema *jsonSchema) (string, error) {
+	content := []chatContentPart{{Type: "text", Text: prompt}}
+
+	if audioPath != "" {
+		audioData, err := os.ReadFile(audioPath)
+		if err != nil {
+			return "", err
+		}
+
+		format := strings.TrimPrefix(path.Ext(audioPath), ".")
+		if format == "" {
+			format = "mp3"
+		}
+		g.logger.Info("Attaching audio", "file", audioPath, "format", format)
+
+		content = append(content, chatContentPart{Type: "input_audio", InputAudio: &chatInputAudio{
+			Data:   base64.StdEncoding.EncodeToString(audioData),
+			Format: format,
+		}})
+	}
+
+	body, err := json.Marshal(chatCompletionRequest{
+		Model: g.model,
+		Messages: []chatMessage{{
+			Role:    "user",
+			Content: content,
+		}},
+		MaxTokens: 65536,
+		ResponseFormat: &chatResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &chatJSONSchema{Name: "sermon_analysis", Schema: schema},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+
+	g.logger.Info("Sending prompt to model", "model", g.model, "base_url", g.baseURL)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", newStatusError(resp, string(respBody))
+	}
+
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(respBody, &completion); err != nil {
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("%w: model returned no choices", ErrMalformedResponse)
+	}
+
+	text := completion.Choices[0].Message.Content
+	g.logger.Info("Model response", "response", text)
+	return text, nil
+}
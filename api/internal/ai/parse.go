@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// parseResult parses and validates the model's text response into an AnalysisResult.
+// Providers constrain the output to resultSchema when they can, but not all of them
+// honour it, so near misses (code fences, chatter around the object, trailing commas,
+// unquoted keys) are repaired before giving up.
+func parseResult(raw string) (AnalysisResult, error) {
+	var result AnalysisResult
+	if err := unmarshalLenient(raw, &result); err != nil {
+		return AnalysisResult{}, err
+	}
+
+	if err := result.Validate(); err != nil {
+		return AnalysisResult{}, err
+	}
+
+	return result, nil
+}
+
+// unmarshalLenient unmarshals raw as is, falling back to repairJSON when it isn't valid
+func unmarshalLenient(raw string, v any) error {
+	err := json.Unmarshal([]byte(raw), v)
+	if err == nil {
+		return nil
+	}
+
+	repaired := repairJSON(raw)
+	if repairedErr := json.Unmarshal([]byte(repaired), v); repairedErr != nil {
+		return errors.Join(ErrMalformedResponse, err, fmt.Errorf("failed to unmarshal response: %s", raw))
+	}
+	return nil
+}
+
+// repairJSON makes a best effort to turn almost-JSON into JSON
+func repairJSON(raw string) string {
+	text := strings.TrimSpace(raw)
+
+	// only keep the outermost object, dropping fences and anything the model said around it
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start == -1 || end < start {
+		return text
+	}
+	text = text[start : end+1]
+
+	var out strings.Builder
+	out.Grow(len(text))
+
+	inString := false
+	escaped := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			case c == '\n':
+				// raw newlines aren't allowed in JSON strings
+				out.WriteString(`\n`)
+				continue
+			case c == '\t':
+				out.WriteString(`\t`)
+				continue
+			case c == '\r':
+				continue
+			}
+			out.WriteByte(c)
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out.WriteByte(c)
+		case c == ',':
+			// drop trailing commas
+			if next := nextNonSpace(text, i+1); next == '}' || next == ']' {
+				continue
+			}
+			out.WriteByte(c)
+		case isIdentStart(c):
+			// quote bare object keys
+			j := i
+			for j < len(text) && isIdentPart(text[j]) {
+				j++
+			}
+			word := text[i:j]
+			if nextNonSpace(text, j) == ':' {
+				out.WriteString(`"` + word + `"`)
+			} else {
+				out.WriteString(word)
+			}
+			i = j - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String()
+}
+
+// nextNonSpace returns the first non whitespace byte at or after i, or 0 at the end of s
+func nextNonSpace(s string, i int) byte {
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return s[i]
+		}
+	}
+	return 0
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
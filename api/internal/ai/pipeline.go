@@ -0,0 +1,226 @@
+package ai
+
+import (
+	"api/internal/audio"
+	"api/internal/models"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//go:embed prompt_transcribe.txt
+var transcribePrompt string
+
+//go:embed prompt_chunk.txt
+var chunkPrompt string
+
+//go:embed prompt_merge.txt
+var mergePrompt string
+
+const (
+	defaultChunkThreshold = 45 * time.Minute
+	defaultChunkLength    = 20 * time.Minute
+	defaultChunkOverlap   = time.Minute
+)
+
+// generator is the one thing a provider has to do for the analysis pipeline
+type generator interface {
+	// generate sends the prompt, along with the recording at audioPath unless it's empty,
+	// and returns the model's text response, which should be JSON matching schema
+	generate(ctx context.Context, prompt string, audioPath string, schema *jsonSchema) (string, error)
+}
+
+// ChunkConfig decides when and how long recordings and transcripts are handled in pieces
+type ChunkConfig struct {
+	Threshold time.Duration // recordings or transcripts longer than this are chunked, 0 never chunks
+	Length    time.Duration
+	Overlap   time.Duration
+	FFmpeg    audio.NormalizeConfig
+}
+
+// ChunkConfigFromEnv reads ANALYSIS_CHUNK_THRESHOLD (default 45m), ANALYSIS_CHUNK_LENGTH (default 20m)
+// and ANALYSIS_CHUNK_OVERLAP (default 1m)
+func ChunkConfigFromEnv() ChunkConfig {
+	cfg := ChunkConfig{
+		Threshold: defaultChunkThreshold,
+		Length:    defaultChunkLength,
+		Overlap:   defaultChunkOverlap,
+		FFmpeg:    audio.NormalizeConfigFromEnv(),
+	}
+	if d, err := time.ParseDuration(os.Getenv("ANALYSIS_CHUNK_THRESHOLD")); err == nil && d >= 0 {
+		cfg.Threshold = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("ANALYSIS_CHUNK_LENGTH")); err == nil && d > 0 {
+		cfg.Length = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("ANALYSIS_CHUNK_OVERLAP")); err == nil && d >= 0 {
+		cfg.Overlap = d
+	}
+	return cfg
+}
+
+// chunkResult is what the model produces for a single chunk of a long transcript
+type chunkResult struct {
+	Summary string      `json:"summary"`
+	Details []chunkNote `json:"notes"`
+}
+
+// chunkNote is a models.SermonDetail without the fields we fill in ourselves
+type chunkNote struct {
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	KeyVerse       string `json:"key_verse"`
+	RelevantVerses string `json:"relevant_verses"`
+}
+
+// chunkSchema is the JSON schema of a chunkResult
+var chunkSchema = schemaFor(reflect.TypeOf(chunkResult{}), "")
+
+// pipeline is the Analyzer shared by the model backed providers. Recordings are transcribed
+// (in overlapping chunks when they're long) and the notes are taken from the transcript, again
+// in chunks that are then merged by the model when the transcript is long.
+type pipeline struct {
+	ctx    context.Context
+	gen    generator
+	chunks ChunkConfig
+	logger *slog.Logger
+}
+
+func newPipeline(ctx context.Context, gen generator, logger *slog.Logger) *pipeline {
+	return &pipeline{
+		ctx:    ctx,
+		gen:    gen,
+		chunks: ChunkConfigFromEnv(),
+		logger: logger,
+	}
+}
+
+func (p *pipeline) Transcribe(job models.SermonAnalysisJob, source AudioSource) (Transcript, error) {
+	logger := p.logger.With("job_id", job.Id)
+
+	logger.Info("Fetching sermon audio", "url", job.AudioURL)
+	audioPath, cleanup, err := source(p.ctx)
+	if err != nil {
+		return Transcript{}, err
+	}
+	defer cleanup()
+
+	duration, ok := p.duration(audioPath, logger)
+	if !ok || !p.chunked(duration) {
+		logger.Info("Transcribing sermon", "file", audioPath, "duration", duration.String())
+		text, err := p.gen.generate(p.ctx, transcribePrompt, audioPath, transcriptSchema)
+		if err != nil {
+			return Transcript{}, err
+		}
+		return parseTranscript(text)
+	}
+
+	chunks, err := audio.Split(p.ctx, p.chunks.FFmpeg, audioPath, duration, p.chunks.Length, p.chunks.Overlap)
+	if err != nil {
+		return Transcript{}, err
+	}
+	defer func() {
+		for _, chunk := range chunks {
+			chunk.Remove()
+		}
+	}()
+	logger.Info("Transcribing sermon in chunks", "duration", duration.String(), "chunks", len(chunks))
+
+	transcripts := make([]Transcript, len(chunks))
+	for i, chunk := range chunks {
+		logger.Info("Transcribing chunk", "chunk", i+1, "start", chunk.Start.String(), "end", chunk.End.String())
+		text, err := p.gen.generate(p.ctx, transcribePrompt, chunk.Path, transcriptSchema)
+		if err != nil {
+			return Transcript{}, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+		if transcripts[i], err = parseTranscript(text); err != nil {
+			return Transcript{}, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+	}
+
+	return stitch(chunks, transcripts), nil
+}
+
+func (p *pipeline) AnalyzeTranscript(job models.SermonAnalysisJob, transcript Transcript) (AnalysisResult, error) {
+	logger := p.logger.With("job_id", job.Id)
+
+	duration := transcript.Duration()
+	if !p.chunked(duration) {
+		logger.Info("Analyzing transcript", "segments", len(transcript.Segments), "duration", duration.String())
+		text, err := p.gen.generate(p.ctx, prompt+transcript.String(), "", resultSchema)
+		if err != nil {
+			return AnalysisResult{}, err
+		}
+		return parseResult(text)
+	}
+
+	windows, err := audio.Chunks(duration, p.chunks.Length, p.chunks.Overlap)
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	logger.Info("Analyzing transcript in chunks", "duration", duration.String(), "chunks", len(windows))
+
+	results := make([]chunkResult, len(windows))
+	for i, window := range windows {
+		chunkText := fmt.Sprintf(chunkPrompt, i+1, len(windows), audio.FormatOffset(window.Start), audio.FormatOffset(window.End), audio.FormatOffset(duration))
+
+		logger.Info("Analyzing chunk", "chunk", i+1, "start", window.Start.String(), "end", window.End.String())
+		text, err := p.gen.generate(p.ctx, chunkText+transcript.Window(window).String(), "", chunkSchema)
+		if err != nil {
+			return AnalysisResult{}, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+		if err := unmarshalLenient(text, &results[i]); err != nil {
+			return AnalysisResult{}, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+	}
+
+	return p.merge(windows, results, logger)
+}
+
+// chunked reports whether something this long is handled in chunks
+func (p *pipeline) chunked(duration time.Duration) bool {
+	return p.chunks.Threshold > 0 && duration > p.chunks.Threshold
+}
+
+// duration probes the recording's length, which needs ffprobe. Without it recordings are never chunked.
+func (p *pipeline) duration(audioPath string, logger *slog.Logger) (time.Duration, bool) {
+	if p.chunks.Threshold == 0 {
+		return 0, false
+	}
+	if err := p.chunks.FFmpeg.Available(); err != nil {
+		logger.Warn("Can't chunk long recordings", "error", err.Error())
+		return 0, false
+	}
+
+	duration, err := audio.Duration(p.ctx, p.chunks.FFmpeg, audioPath)
+	if err != nil {
+		logger.Warn("Unable to read the recording's duration, transcribing it whole", "error", err.Error())
+		return 0, false
+	}
+	return duration, true
+}
+
+// merge has the model combine the per chunk notes into the final analysis
+func (p *pipeline) merge(windows []audio.Clip, results []chunkResult, logger *slog.Logger) (AnalysisResult, error) {
+	var notes strings.Builder
+	for i, result := range results {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return AnalysisResult{}, err
+		}
+		fmt.Fprintf(&notes, "Part %d (%s to %s):\n%s\n\n", i+1, audio.FormatOffset(windows[i].Start), audio.FormatOffset(windows[i].End), data)
+	}
+
+	logger.Info("Merging chunk notes", "chunks", len(results))
+	text, err := p.gen.generate(p.ctx, mergePrompt+notes.String(), "", resultSchema)
+	if err != nil {
+		return AnalysisResult{}, fmt.Errorf("merge: %w", err)
+	}
+	return parseResult(text)
+}
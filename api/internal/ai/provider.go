@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"api/internal/models"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const DefaultProvider = "gemini"
+
+// Config selects which provider backs an Analyzer and how to reach it.
+// Empty values are filled in with the provider's own defaults.
+type Config struct {
+	Provider string
+	Model    string
+	BaseURL  string
+	APIKey   string
+}
+
+// ConfigFromEnv reads the analyzer config from the environment:
+//
+//	AI_PROVIDER  registered provider name (default "gemini")
+//	AI_MODEL     model name passed to the provider
+//	AI_BASE_URL  base url for http based providers
+//	AI_API_KEY   api key, falls back to the provider specific key (e.g. GEMINI_API_KEY)
+func ConfigFromEnv() Config {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("AI_PROVIDER")))
+	if provider == "" {
+		provider = DefaultProvider
+	}
+
+	return Config{
+		Provider: provider,
+		Model:    os.Getenv("AI_MODEL"),
+		BaseURL:  os.Getenv("AI_BASE_URL"),
+		APIKey:   os.Getenv("AI_API_KEY"),
+	}
+}
+
+// Provider creates an Analyzer for a single job
+type Provider func(cfg Config, job models.SermonAnalysisJob, logger *slog.Logger) (Analyzer, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// RegisterProvider makes a provider available by name. Registering the same name twice replaces the previous provider.
+func RegisterProvider(name string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[strings.ToLower(name)] = provider
+}
+
+// Providers returns the sorted names of all registered providers
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return providersLocked()
+}
+
+func lookupProvider(name string) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	provider, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown ai provider %q (available: %s)", name, strings.Join(providersLocked(), ", "))
+	}
+	return provider, nil
+}
+
+func providersLocked() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withDefaults fills in any empty config values
+func (c Config) withDefaults(model, baseURL, apiKeyEnv string) Config {
+	if c.Model == "" {
+		c.Model = model
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = baseURL
+	}
+	if c.APIKey == "" && apiKeyEnv != "" {
+		c.APIKey = os.Getenv(apiKeyEnv)
+	}
+	return c
+}
@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// jsonSchema is the subset of JSON schema the providers understand for constrained output
+type jsonSchema struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Items       *jsonSchema            `json:"items,omitempty"`
+	MinItems    *int64                 `json:"minItems,omitempty"`
+	MaxItems    *int64                 `json:"maxItems,omitempty"`
+
+	// order of Properties, json objects don't keep one
+	propertyOrder []string
+}
+
+// schemaSkipFields are bookkeeping fields on the models that we fill in, not the model
+var schemaSkipFields = map[string]bool{
+	"id":         true,
+	"sermon_id":  true,
+	"order":      true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// schemaDescriptions guide the model, keyed by the json path of the field
+var schemaDescriptions = map[string]string{
+	"summary":               "A short summary of the whole sermon, at most 6 sentences",
+	"topics":                "3-8 short lowercase themes the sermon is about, e.g. 'grace' or 'forgiveness'",
+	"notes":                 "Notes on every section of the sermon, in the order they were given",
+	"notes.title":           "A short 1 sentence title of what this section of the sermon is about",
+	"notes.description":     "The notes for this section. Plain text, use newlines to organize thoughts",
+	"notes.key_verse":       "The key verses from the main passage covered in this section, e.g. 'Matt 5:4-9'",
+	"notes.relevant_verses": "Other supporting verses as a pipe separated list, e.g. 'Gen 1:1-3|Num 6:12'",
+	"questions":             "1-10 open ended discussion questions for a small group",
+	"questions.title":       "The main question",
+	"questions.description": "Supporting information or (Leader note) context for the question",
+	"segments":              "The transcript split into consecutive segments of a sentence or two, in order",
+	"segments.start_ms":     "When the segment starts, in milliseconds from the start of the recording",
+	"segments.end_ms":       "When the segment ends, in milliseconds from the start of the recording",
+	"segments.text":         "Exactly what was said during the segment",
+}
+
+// schemaLimits sets min/max item counts for arrays, keyed by json path
+var schemaLimits = map[string][2]int64{
+	"topics":    {0, maxTopics},
+	"notes":     {1, 0},
+	"questions": {minQuestions, maxQuestions},
+	"segments":  {1, 0},
+}
+
+// resultSchema is the JSON schema of an AnalysisResult as the model should produce it
+var resultSchema = schemaFor(reflect.TypeOf(AnalysisResult{}), "")
+
+// schemaFor derives a schema from a go type using its json tags
+func schemaFor(t reflect.Type, path string) *jsonSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	schema := &jsonSchema{Description: schemaDescriptions[path]}
+	switch t.Kind() {
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.Slice, reflect.Array:
+		schema.Type = "array"
+		schema.Items = schemaFor(t.Elem(), path)
+		schema.Items.Description = ""
+		if limits, ok := schemaLimits[path]; ok {
+			if limits[0] > 0 {
+				schema.MinItems = &limits[0]
+			}
+			if limits[1] > 0 {
+				schema.MaxItems = &limits[1]
+			}
+		}
+	case reflect.Struct:
+		schema.Type = "object"
+		schema.Properties = map[string]*jsonSchema{}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "" || name == "-" || schemaSkipFields[name] {
+				continue
+			}
+
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			schema.Properties[name] = schemaFor(field.Type, fieldPath)
+			schema.Required = append(schema.Required, name)
+			schema.propertyOrder = append(schema.propertyOrder, name)
+		}
+	}
+
+	return schema
+}
+
+// genaiSchema converts the schema to the Gemini flavour
+func (s *jsonSchema) genaiSchema() *genai.Schema {
+	if s == nil {
+		return nil
+	}
+
+	out := &genai.Schema{
+		Type:             genai.Type(strings.ToUpper(s.Type)),
+		Description:      s.Description,
+		Required:         s.Required,
+		PropertyOrdering: s.propertyOrder,
+		MinItems:         s.MinItems,
+		MaxItems:         s.MaxItems,
+		Items:            s.Items.genaiSchema(),
+	}
+	if len(s.Properties) > 0 {
+		out.Properties = map[string]*genai.Schema{}
+		for name, prop := range s.Properties {
+			out.Properties[name] = prop.genaiSchema()
+		}
+	}
+	return out
+}
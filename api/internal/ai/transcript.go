@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"api/internal/audio"
+	"api/internal/models"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Transcript is a recording turned into timestamped text
+type Transcript struct {
+	Segments []models.TranscriptSegment `json:"segments"`
+}
+
+// transcriptSchema is the JSON schema of a Transcript as the model should produce it
+var transcriptSchema = schemaFor(reflect.TypeOf(Transcript{}), "")
+
+// Duration is where the transcript ends
+func (t Transcript) Duration() time.Duration {
+	if len(t.Segments) == 0 {
+		return 0
+	}
+	return time.Duration(t.Segments[len(t.Segments)-1].EndMs) * time.Millisecond
+}
+
+// Window returns the segments that start inside the clip
+func (t Transcript) Window(clip audio.Clip) Transcript {
+	window := Transcript{}
+	for _, segment := range t.Segments {
+		start := time.Duration(segment.StartMs) * time.Millisecond
+		if start >= clip.Start && (clip.End == 0 || start < clip.End) {
+			window.Segments = append(window.Segments, segment)
+		}
+	}
+	return window
+}
+
+// String formats the transcript for a prompt, one "[h:mm:ss] text" line per segment
+func (t Transcript) String() string {
+	var b strings.Builder
+	for _, segment := range t.Segments {
+		fmt.Fprintf(&b, "[%s] %s\n", audio.FormatOffset(time.Duration(segment.StartMs)*time.Millisecond), strings.TrimSpace(segment.Text))
+	}
+	return b.String()
+}
+
+// Validate checks the transcript has text and sensible timestamps
+func (t Transcript) Validate() error {
+	var errs ValidationErrors
+	add := func(field string, format string, args ...any) {
+		errs = append(errs, &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(t.Segments) == 0 {
+		add("segments", "is empty")
+	}
+	for i, segment := range t.Segments {
+		if strings.TrimSpace(segment.Text) == "" {
+			add(fmt.Sprintf("segments[%d].text", i), "is empty")
+		}
+		if segment.StartMs < 0 || segment.EndMs < segment.StartMs {
+			add(fmt.Sprintf("segments[%d]", i), "ends (%dms) before it starts (%dms)", segment.EndMs, segment.StartMs)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// parseTranscript parses and validates the model's transcription, putting the segments in order
+func parseTranscript(raw string) (Transcript, error) {
+	var transcript Transcript
+	if err := unmarshalLenient(raw, &transcript); err != nil {
+		return Transcript{}, err
+	}
+
+	if err := transcript.Validate(); err != nil {
+		return Transcript{}, err
+	}
+
+	sort.SliceStable(transcript.Segments, func(i, j int) bool {
+		return transcript.Segments[i].StartMs < transcript.Segments[j].StartMs
+	})
+	return transcript, nil
+}
+
+// stitch joins the transcripts of overlapping chunks into one. Each chunk's timestamps are relative
+// to the chunk, and where two chunks overlap the earlier one is used up to the middle of the overlap.
+func stitch(chunks []*audio.Chunk, transcripts []Transcript) Transcript {
+	stitched := Transcript{}
+	for i, transcript := range transcripts {
+		from := time.Duration(0)
+		if i > 0 {
+			from = (chunks[i].Start + chunks[i-1].End) / 2
+		}
+		to := time.Duration(0)
+		if i < len(chunks)-1 {
+			to = (chunks[i+1].Start + chunks[i].End) / 2
+		}
+
+		offset := chunks[i].Start.Milliseconds()
+		for _, segment := range transcript.Segments {
+			segment.StartMs += offset
+			segment.EndMs += offset
+
+			start := time.Duration(segment.StartMs) * time.Millisecond
+			if start < from || (to > 0 && start >= to) {
+				continue
+			}
+			stitched.Segments = append(stitched.Segments, segment)
+		}
+	}
+	return stitched
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	minQuestions = 1
+	maxQuestions = 10
+	maxTopics    = 8
+)
+
+// ValidationError is a single problem with an otherwise well formed model response
+type ValidationError struct {
+	Field   string // json path of the offending field, e.g. notes[2].title
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors is every problem found in a model response.
+// It matches ErrMalformedResponse with errors.Is, so invalid responses get retried.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "invalid model response: " + strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrMalformedResponse
+}
+
+// Validate checks the result has everything we're going to save
+func (r AnalysisResult) Validate() error {
+	var errs ValidationErrors
+	add := func(field string, format string, args ...any) {
+		errs = append(errs, &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(r.Summary) == "" {
+		add("summary", "is empty")
+	}
+
+	if len(r.Details) == 0 {
+		add("notes", "has no sections")
+	}
+	for i, detail := range r.Details {
+		if strings.TrimSpace(detail.Title) == "" {
+			add(fmt.Sprintf("notes[%d].title", i), "is empty")
+		}
+	}
+
+	if n := len(r.Questions); n < minQuestions || n > maxQuestions {
+		add("questions", "has %d questions, expected %d-%d", n, minQuestions, maxQuestions)
+	}
+	for i, question := range r.Questions {
+		if strings.TrimSpace(question.Title) == "" {
+			add(fmt.Sprintf("questions[%d].title", i), "is empty")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
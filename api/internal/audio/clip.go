@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// offsetPattern matches the same timestamps as the analysis_jobs offset fields
+var offsetPattern = regexp.MustCompile(`^(\d+:){0,2}\d+(\.\d+)?$`)
+
+// Clip is the window of a recording to analyze. A zero Start or End means the start or end of the recording.
+type Clip struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// IsZero reports whether the clip is the whole recording
+func (c Clip) IsZero() bool {
+	return c.Start == 0 && c.End == 0
+}
+
+// ParseClip parses a start and end offset, either of which may be empty
+func ParseClip(start string, end string) (Clip, error) {
+	var clip Clip
+	var err error
+
+	if clip.Start, err = ParseOffset(start); err != nil {
+		return Clip{}, fmt.Errorf("invalid start offset: %w", err)
+	}
+	if clip.End, err = ParseOffset(end); err != nil {
+		return Clip{}, fmt.Errorf("invalid end offset: %w", err)
+	}
+	if clip.End != 0 && clip.End <= clip.Start {
+		return Clip{}, errors.New("end offset must be after the start offset")
+	}
+
+	return clip, nil
+}
+
+// ParseOffset parses a timestamp like "41:20", "1:18:05" or "95.5" (seconds). Empty is 0.
+func ParseOffset(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	if !offsetPattern.MatchString(s) {
+		return 0, fmt.Errorf("%q is not a valid timestamp, expected [[h:]m:]s", s)
+	}
+
+	var seconds float64
+	for i, part := range strings.Split(s, ":") {
+		value, _ := strconv.ParseFloat(part, 64)
+		// minutes and seconds after the first part can't roll over
+		if i > 0 && value >= 60 {
+			return 0, fmt.Errorf("%q is not a valid timestamp", s)
+		}
+		seconds = seconds*60 + value
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// FormatOffset formats an offset as h:mm:ss, dropping any fraction of a second
+func FormatOffset(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
@@ -0,0 +1,227 @@
+// Package audio fetches and prepares sermon recordings before they're handed to a model.
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDownloadTimeout = 30 * time.Minute
+	defaultMaxBytes        = 500 << 20 // same as the sermons.audio_file upload limit
+	defaultMaxResumes      = 5
+)
+
+var (
+	// ErrTooLarge means the recording is bigger than the configured byte cap
+	ErrTooLarge = errors.New("audio file is too large")
+	// ErrNotAudio means the url didn't point at something that looks like audio or video
+	ErrNotAudio = errors.New("url is not an audio file")
+)
+
+// StatusError is returned when the audio url answers with an unexpected status
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("bad status downloading audio: %s", e.Status)
+}
+
+// DownloadConfig limits how long and how much a download may take
+type DownloadConfig struct {
+	Timeout    time.Duration // for the whole download, resumes included
+	MaxBytes   int64
+	MaxResumes int // times an interrupted download is picked back up with a range request
+	Client     *http.Client
+}
+
+// DownloadConfigFromEnv reads AUDIO_DOWNLOAD_TIMEOUT (default 30m) and AUDIO_MAX_BYTES (default 500MB)
+func DownloadConfigFromEnv() DownloadConfig {
+	cfg := DownloadConfig{
+		Timeout:    defaultDownloadTimeout,
+		MaxBytes:   defaultMaxBytes,
+		MaxResumes: defaultMaxResumes,
+		Client:     http.DefaultClient,
+	}
+	if d, err := time.ParseDuration(os.Getenv("AUDIO_DOWNLOAD_TIMEOUT")); err == nil && d > 0 {
+		cfg.Timeout = d
+	}
+	if n, err := strconv.ParseInt(os.Getenv("AUDIO_MAX_BYTES"), 10, 64); err == nil && n > 0 {
+		cfg.MaxBytes = n
+	}
+	return cfg
+}
+
+// File is a recording in a local temp file
+type File struct {
+	Path     string
+	MIMEType string
+	Size     int64
+}
+
+// Remove deletes the temp file
+func (f *File) Remove() error {
+	return os.Remove(f.Path)
+}
+
+// Download streams url into a temp file named after the sniffed audio type.
+// Interrupted transfers are resumed with range requests when the server supports them.
+// The temp file is always removed when an error is returned, otherwise the caller must Remove it.
+func Download(ctx context.Context, cfg DownloadConfig, url string) (*File, error) {
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	tmpFile, err := os.CreateTemp("", "sermon-*")
+	if err != nil {
+		return nil, err
+	}
+	file, err := download(ctx, cfg, url, tmpFile)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, err
+	}
+
+	// name it by what it actually is, so anything going by extension gets it right
+	path := tmpFile.Name() + Extension(file.MIMEType)
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, err
+	}
+	file.Path = path
+
+	return file, nil
+}
+
+func download(ctx context.Context, cfg DownloadConfig, url string, out *os.File) (*File, error) {
+	var (
+		written     int64
+		total       int64 = -1
+		contentType string
+		resumes     int
+	)
+
+	for {
+		resp, err := get(ctx, cfg.Client, url, written)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case written > 0 && resp.StatusCode == http.StatusPartialContent:
+			// picking up where we left off
+		case resp.StatusCode == http.StatusOK:
+			if written > 0 {
+				// the server ignored the range, start over
+				if err := restart(out); err != nil {
+					resp.Body.Close()
+					return nil, err
+				}
+				written = 0
+			}
+			total = resp.ContentLength
+			contentType = resp.Header.Get("Content-Type")
+		default:
+			resp.Body.Close()
+			return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+
+		if cfg.MaxBytes > 0 && total > cfg.MaxBytes {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: %d bytes, the limit is %d", ErrTooLarge, total, cfg.MaxBytes)
+		}
+
+		n, err := copyCapped(out, resp.Body, cfg.MaxBytes-written, cfg.MaxBytes)
+		resp.Body.Close()
+		written += n
+		if err == nil && total >= 0 && written < total {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			break
+		}
+
+		canResume := resp.Header.Get("Accept-Ranges") == "bytes" || resp.StatusCode == http.StatusPartialContent
+		if errors.Is(err, ErrTooLarge) || ctx.Err() != nil || !canResume || n == 0 || resumes >= cfg.MaxResumes {
+			return nil, err
+		}
+		resumes++
+	}
+
+	mimeType, err := sniff(out, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{MIMEType: mimeType, Size: written}, nil
+}
+
+func get(ctx context.Context, client *http.Client, url string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return client.Do(req)
+}
+
+// copyCapped copies src to dst, failing with ErrTooLarge past remaining bytes (unless limit is 0)
+func copyCapped(dst io.Writer, src io.Reader, remaining int64, limit int64) (int64, error) {
+	if limit <= 0 {
+		return io.Copy(dst, src)
+	}
+
+	n, err := io.Copy(dst, io.LimitReader(src, remaining+1))
+	if n > remaining {
+		return n, fmt.Errorf("%w: the limit is %d bytes", ErrTooLarge, limit)
+	}
+	return n, err
+}
+
+func restart(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+// sniff works out the file's type from its first bytes, falling back to the Content-Type header
+func sniff(f *os.File, contentType string) (string, error) {
+	head := make([]byte, 512)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	if mimeType := DetectMIMEType(head[:n]); mimeType != "" {
+		return mimeType, nil
+	}
+
+	mimeType, _, _ := strings.Cut(contentType, ";")
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if strings.HasPrefix(mimeType, "audio/") || strings.HasPrefix(mimeType, "video/") {
+		return mimeType, nil
+	}
+
+	return "", fmt.Errorf("%w: content type %q", ErrNotAudio, contentType)
+}
@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"bytes"
+	"mime"
+	"path"
+	"strings"
+)
+
+// extensions maps the audio types we recognise to the extension files of that type get
+var extensions = map[string]string{
+	"audio/mpeg":  ".mp3",
+	"audio/wav":   ".wav",
+	"audio/x-wav": ".wav",
+	"audio/ogg":   ".ogg",
+	"audio/flac":  ".flac",
+	"audio/aac":   ".aac",
+	"audio/mp4":   ".m4a",
+	"audio/x-m4a": ".m4a",
+	"audio/webm":  ".webm",
+	"video/mp4":   ".mp4",
+	"video/webm":  ".webm",
+}
+
+// mimeTypes is the reverse of extensions, the system mime table often doesn't know audio types
+var mimeTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".ogg":  "audio/ogg",
+	".flac": "audio/flac",
+	".aac":  "audio/aac",
+	".m4a":  "audio/mp4",
+	".webm": "audio/webm",
+	".mp4":  "video/mp4",
+}
+
+// DetectMIMEType recognises common audio containers by their magic bytes, returning "" if it can't tell
+func DetectMIMEType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("ID3")):
+		return "audio/mpeg"
+	case len(head) >= 12 && bytes.Equal(head[0:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return "audio/wav"
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return "audio/flac"
+	case len(head) >= 12 && bytes.Equal(head[4:8], []byte("ftyp")):
+		// mp4 family, the brand says whether it's audio only
+		if brand := string(head[8:12]); brand == "M4A " || brand == "M4B " {
+			return "audio/mp4"
+		}
+		return "video/mp4"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "audio/webm"
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xF6 == 0xF0:
+		// adts frame sync, layer 0
+		return "audio/aac"
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		// mpeg audio frame sync without an id3 tag
+		return "audio/mpeg"
+	}
+	return ""
+}
+
+// Extension returns the file extension (with the dot) for an audio mime type
+func Extension(mimeType string) string {
+	if ext, ok := extensions[mimeType]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// MIMEType guesses the mime type of an audio file from its extension
+func MIMEType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if mimeType, ok := mimeTypes[ext]; ok {
+		return mimeType
+	}
+	return mime.TypeByExtension(ext)
+}
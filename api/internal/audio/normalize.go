@@ -0,0 +1,168 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSampleRate = 16000
+	defaultBitrate    = "48k"
+	// quieter than this counts as silence when trimming
+	silenceThreshold = "-50dB"
+)
+
+// ErrNoFFmpeg means normalization is on but ffmpeg isn't installed
+var ErrNoFFmpeg = errors.New("ffmpeg not found")
+
+// NormalizeConfig controls how recordings are re-encoded before they're sent to a model
+type NormalizeConfig struct {
+	Enabled     bool
+	FFmpegPath  string
+	FFprobePath string
+	SampleRate  int    // Hz
+	Bitrate     string // ffmpeg bitrate, e.g. 48k
+	TrimSilence bool   // trim silence from the start and end
+}
+
+// NormalizeConfigFromEnv reads AUDIO_NORMALIZE (default true), AUDIO_SAMPLE_RATE (default 16000),
+// AUDIO_BITRATE (default 48k), AUDIO_TRIM_SILENCE (default false), FFMPEG_PATH and FFPROBE_PATH
+func NormalizeConfigFromEnv() NormalizeConfig {
+	cfg := NormalizeConfig{
+		Enabled:     os.Getenv("AUDIO_NORMALIZE") != "false",
+		FFmpegPath:  "ffmpeg",
+		FFprobePath: "ffprobe",
+		SampleRate:  defaultSampleRate,
+		Bitrate:     defaultBitrate,
+		TrimSilence: os.Getenv("AUDIO_TRIM_SILENCE") == "true",
+	}
+	if p := os.Getenv("FFMPEG_PATH"); p != "" {
+		cfg.FFmpegPath = p
+	}
+	if p := os.Getenv("FFPROBE_PATH"); p != "" {
+		cfg.FFprobePath = p
+	}
+	if n, err := strconv.Atoi(os.Getenv("AUDIO_SAMPLE_RATE")); err == nil && n > 0 {
+		cfg.SampleRate = n
+	}
+	if b := os.Getenv("AUDIO_BITRATE"); b != "" {
+		cfg.Bitrate = b
+	}
+	return cfg
+}
+
+// Available reports whether ffmpeg and ffprobe can be found
+func (c NormalizeConfig) Available() error {
+	for _, bin := range []string{c.FFmpegPath, c.FFprobePath} {
+		if _, err := exec.LookPath(bin); err != nil {
+			return fmt.Errorf("%w: %w", ErrNoFFmpeg, err)
+		}
+	}
+	return nil
+}
+
+// Normalized is a re-encoded recording
+type Normalized struct {
+	File
+	Duration time.Duration
+}
+
+// Normalize re-encodes the clip of the recording at inPath into a small mono mp3 suited to speech:
+// only the audio track is kept, downmixed and resampled, optionally with silence trimmed.
+// The output is a new temp file the caller must Remove, inPath is left alone.
+func Normalize(ctx context.Context, cfg NormalizeConfig, inPath string, clip Clip) (*Normalized, error) {
+	out, err := os.CreateTemp("", "sermon-*.mp3")
+	if err != nil {
+		return nil, err
+	}
+	out.Close()
+
+	args := []string{"-hide_banner", "-nostdin", "-y"}
+	// as input options these seek in the source, so the timestamps match the original recording
+	if clip.Start > 0 {
+		args = append(args, "-ss", ffmpegTime(clip.Start))
+	}
+	if clip.End > 0 {
+		args = append(args, "-to", ffmpegTime(clip.End))
+	}
+	args = append(args, "-i", inPath, "-vn", "-ac", "1", "-ar", strconv.Itoa(cfg.SampleRate), "-b:a", cfg.Bitrate)
+	if cfg.TrimSilence {
+		// silenceremove only trims the start, so trim, flip, trim again and flip back for the end
+		trim := "silenceremove=start_periods=1:start_duration=0.5:start_threshold=" + silenceThreshold
+		args = append(args, "-af", trim+",areverse,"+trim+",areverse")
+	}
+	args = append(args, "-codec:a", "libmp3lame", out.Name())
+
+	if err := run(ctx, cfg.FFmpegPath, args...); err != nil {
+		os.Remove(out.Name())
+		return nil, err
+	}
+
+	normalized, err := probe(ctx, cfg, out.Name())
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// probe reads the duration and size of an audio file
+func probe(ctx context.Context, cfg NormalizeConfig, path string) (*Normalized, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := Duration(ctx, cfg, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Normalized{
+		File:     File{Path: path, MIMEType: MIMEType(path), Size: info.Size()},
+		Duration: duration,
+	}, nil
+}
+
+// Duration reads the length of an audio file with ffprobe
+func Duration(ctx context.Context, cfg NormalizeConfig, path string) (time.Duration, error) {
+	cmd := exec.CommandContext(ctx, cfg.FFprobePath, "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe returned an invalid duration %q: %w", output, err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ffmpegTime formats a duration the way ffmpeg takes it
+func ffmpegTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// run runs an ffmpeg command, including the tail of its output in the error when it fails
+func run(ctx context.Context, bin string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 500 {
+			msg = msg[len(msg)-500:]
+		}
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, msg)
+	}
+	return nil
+}
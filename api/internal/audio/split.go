@@ -0,0 +1,56 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Chunk is one piece of a recording split up for analysis
+type Chunk struct {
+	*Normalized
+	Clip // where the chunk sits in the recording
+}
+
+// Chunks returns the windows a recording of the given duration splits into: each is length
+// long, apart from the last, and starts overlap before the previous one ends
+func Chunks(duration time.Duration, length time.Duration, overlap time.Duration) ([]Clip, error) {
+	if length <= 0 || overlap < 0 || overlap >= length {
+		return nil, errors.New("chunk length must be positive and longer than the overlap")
+	}
+
+	clips := []Clip{}
+	for start := time.Duration(0); start < duration; start += length - overlap {
+		end := min(start+length, duration)
+		clips = append(clips, Clip{Start: start, End: end})
+		if end == duration {
+			break
+		}
+	}
+	return clips, nil
+}
+
+// Split cuts the recording at path into overlapping chunks (see Chunks), each re-encoded to its own temp file.
+// On success the caller must Remove every chunk, on error they're already removed.
+func Split(ctx context.Context, cfg NormalizeConfig, path string, duration time.Duration, length time.Duration, overlap time.Duration) ([]*Chunk, error) {
+	clips, err := Chunks(duration, length, overlap)
+	if err != nil {
+		return nil, err
+	}
+
+	// trimming silence would throw off where each chunk sits in the recording
+	cfg.TrimSilence = false
+
+	chunks := make([]*Chunk, 0, len(clips))
+	for _, clip := range clips {
+		normalized, err := Normalize(ctx, cfg, path, clip)
+		if err != nil {
+			for _, chunk := range chunks {
+				chunk.Remove()
+			}
+			return nil, err
+		}
+		chunks = append(chunks, &Chunk{Normalized: normalized, Clip: clip})
+	}
+	return chunks, nil
+}
@@ -0,0 +1,171 @@
+package bible
+
+import (
+	"api/internal/scripture"
+	"bufio"
+	"compress/gzip"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultTranslation = "kjv"
+
+//go:embed texts
+var texts embed.FS
+
+// ErrNoTranslation means the translation isn't bundled
+var ErrNoTranslation = errors.New("translation not available")
+
+// Verse is the text of a single verse
+type Verse struct {
+	Chapter int    `json:"chapter"`
+	Verse   int    `json:"verse"`
+	Text    string `json:"text"`
+}
+
+// Translation is the full text of one translation, loaded from texts
+type Translation struct {
+	ID     string
+	verses map[string][][]string // book -> chapter -> verse -> text
+}
+
+// DefaultTranslation reads BIBLE_TRANSLATION (default kjv)
+func DefaultTranslation() string {
+	if id := os.Getenv("BIBLE_TRANSLATION"); id != "" {
+		return strings.ToLower(id)
+	}
+	return defaultTranslation
+}
+
+// Translations lists the ids of the bundled translations
+func Translations() []string {
+	ids := []string{}
+	files, _ := fs.Glob(texts, "texts/*.tsv.gz")
+	for _, file := range files {
+		ids = append(ids, strings.TrimSuffix(strings.TrimPrefix(file, "texts/"), ".tsv.gz"))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+var (
+	loadedMu sync.Mutex
+	loaded   = map[string]*Translation{}
+)
+
+// Load returns the bundled translation, reading it the first time it's asked for
+func Load(id string) (*Translation, error) {
+	id = strings.ToLower(id)
+
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+
+	if translation, ok := loaded[id]; ok {
+		return translation, nil
+	}
+
+	file, err := texts.Open("texts/" + id + ".tsv.gz")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoTranslation, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("bible text %s: %w", id, err)
+	}
+	defer reader.Close()
+
+	translation := &Translation{ID: id, verses: map[string][][]string{}}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		if err := translation.add(scanner.Text()); err != nil {
+			return nil, fmt.Errorf("bible text %s line %d: %w", id, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("bible text %s: %w", id, err)
+	}
+
+	loaded[id] = translation
+	return translation, nil
+}
+
+// add stores a BOOK\tCHAPTER\tVERSE\tTEXT line
+func (t *Translation) add(line string) error {
+	fields := strings.SplitN(line, "\t", 4)
+	if len(fields) != 4 {
+		return errors.New("expected BOOK, CHAPTER, VERSE and TEXT separated by tabs")
+	}
+
+	book, ok := scripture.BookByID(fields[0])
+	if !ok {
+		return fmt.Errorf("%w %q", scripture.ErrUnknownBook, fields[0])
+	}
+	chapter, err := strconv.Atoi(fields[1])
+	if err != nil || book.VersesIn(chapter) == 0 {
+		return fmt.Errorf("%w: %s %s", scripture.ErrOutOfRange, book.Abbrev, fields[1])
+	}
+	verse, err := strconv.Atoi(fields[2])
+	if err != nil || verse < 1 || verse > book.VersesIn(chapter) {
+		return fmt.Errorf("%w: %s %d:%s", scripture.ErrOutOfRange, book.Abbrev, chapter, fields[2])
+	}
+
+	if t.verses[book.ID] == nil {
+		t.verses[book.ID] = make([][]string, book.Chapters())
+	}
+	if t.verses[book.ID][chapter-1] == nil {
+		t.verses[book.ID][chapter-1] = make([]string, book.VersesIn(chapter))
+	}
+	t.verses[book.ID][chapter-1][verse-1] = strings.TrimSpace(fields[3])
+	return nil
+}
+
+// Passage returns the text of every verse in the reference, leaving out any the translation doesn't have
+func (t *Translation) Passage(ref scripture.Ref) ([]Verse, error) {
+	if err := ref.Validate(); err != nil {
+		return nil, err
+	}
+	book, _ := scripture.BookByID(ref.Book)
+
+	verses := []Verse{}
+	for chapter := ref.Chapter; chapter <= ref.EndChapter; chapter++ {
+		first, last := 1, book.VersesIn(chapter)
+		if !ref.WholeChapters() && chapter == ref.Chapter {
+			first = ref.Verse
+		}
+		if !ref.WholeChapters() && chapter == ref.EndChapter {
+			last = ref.EndVerse
+		}
+
+		for verse := first; verse <= last; verse++ {
+			if text := t.text(book.ID, chapter, verse); text != "" {
+				verses = append(verses, Verse{Chapter: chapter, Verse: verse, Text: text})
+			}
+		}
+	}
+	return verses, nil
+}
+
+// text is a single verse, empty if the translation doesn't have it
+func (t *Translation) text(book string, chapter int, verse int) string {
+	chapters := t.verses[book]
+	if chapters == nil || chapters[chapter-1] == nil {
+		return ""
+	}
+	return chapters[chapter-1][verse-1]
+}
@@ -0,0 +1,187 @@
+package captions
+
+import (
+	"api/internal/models"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxLineLength is the usual broadcast limit, short enough to read at a glance
+	DefaultMaxLineLength = 42
+	DefaultMaxLines      = 2
+	// a cue is never shown for less than this, even if the segment is shorter
+	minCueDuration = 500 * time.Millisecond
+)
+
+// Options controls how transcript segments are broken into cues
+type Options struct {
+	MaxLineLength int // characters per line
+	MaxLines      int // lines per cue
+}
+
+// DefaultOptions are the limits used when rendering captions
+func DefaultOptions() Options {
+	return Options{MaxLineLength: DefaultMaxLineLength, MaxLines: DefaultMaxLines}
+}
+
+// Cue is a single caption, shown from Start to End
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// Cues wraps each segment's text to the line limits, splitting segments that don't fit in one cue into
+// several. The segment's time is shared between its cues by how much text each one has.
+func Cues(segments []models.TranscriptSegment, opts Options) []Cue {
+	if opts.MaxLineLength <= 0 {
+		opts.MaxLineLength = DefaultMaxLineLength
+	}
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = DefaultMaxLines
+	}
+
+	cues := []Cue{}
+	for i, segment := range segments {
+		lines := wrap(segment.Text, opts.MaxLineLength)
+		if len(lines) == 0 {
+			continue
+		}
+
+		start := time.Duration(segment.StartMs) * time.Millisecond
+		end := time.Duration(segment.EndMs) * time.Millisecond
+		if end-start < minCueDuration {
+			end = start + minCueDuration
+		}
+		// don't run into the next segment
+		if i+1 < len(segments) {
+			if next := time.Duration(segments[i+1].StartMs) * time.Millisecond; next > start && end > next {
+				end = next
+			}
+		}
+
+		groups := [][]string{}
+		for len(lines) > 0 {
+			n := min(opts.MaxLines, len(lines))
+			groups = append(groups, lines[:n])
+			lines = lines[n:]
+		}
+
+		total := 0
+		for _, group := range groups {
+			total += length(group)
+		}
+
+		written := 0
+		for j, group := range groups {
+			cue := Cue{Start: start + (end-start)*time.Duration(written)/time.Duration(total), Lines: group}
+			written += length(group)
+			cue.End = start + (end-start)*time.Duration(written)/time.Duration(total)
+			if j == len(groups)-1 {
+				cue.End = end
+			}
+			cues = append(cues, cue)
+		}
+	}
+	return cues
+}
+
+// WriteVTT writes the cues as a WebVTT file
+func WriteVTT(w io.Writer, cues []Cue) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return err
+	}
+	for i, cue := range cues {
+		lines := make([]string, len(cue.Lines))
+		for j, line := range cue.Lines {
+			lines[j] = escapeVTT(line)
+		}
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(cue.Start, '.'), timestamp(cue.End, '.'), strings.Join(lines, "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSRT writes the cues as a SubRip file
+func WriteSRT(w io.Writer, cues []Cue) error {
+	for i, cue := range cues {
+		lines := make([]string, len(cue.Lines))
+		for j, line := range cue.Lines {
+			lines[j] = escapeSRT(line)
+		}
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(cue.Start, ','), timestamp(cue.End, ','), strings.Join(lines, "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrap breaks text into lines of at most limit characters on word boundaries. A word longer
+// than a line is hard broken.
+func wrap(text string, limit int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > limit {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:limit]))
+			word = string(runes[limit:])
+		}
+
+		switch {
+		case word == "":
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= limit:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// length counts the characters in a cue's lines
+func length(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		n += utf8.RuneCountInString(line)
+	}
+	return max(n, 1)
+}
+
+// timestamp formats a cue time as hh:mm:ss.mmm, with sep before the milliseconds
+func timestamp(d time.Duration, sep byte) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeVTT escapes the characters WebVTT treats as markup. Escaping > also keeps "-->" out of the text.
+func escapeVTT(s string) string {
+	return vttEscaper.Replace(s)
+}
+
+var srtEscaper = strings.NewReplacer("<", "‹", ">", "›")
+
+// escapeSRT keeps text from being read as a timing line or formatting tags. SRT has no entities,
+// so players would show &lt; literally, and the brackets are swapped for look-alikes instead.
+func escapeSRT(s string) string {
+	return srtEscaper.Replace(s)
+}
@@ -0,0 +1,42 @@
+package embeddings
+
+import (
+	"context"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	RegisterProvider("fake", func(cfg Config) (Embedder, error) {
+		return FakeEmbedder{}, nil
+	})
+}
+
+// FakeDimensions is the length of a FakeEmbedder vector
+const FakeDimensions = 256
+
+var fakeWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// FakeEmbedder is a deterministic Embedder for tests and running locally without a model. It hashes
+// each word into a bucket, so texts are only similar when they share words.
+type FakeEmbedder struct{}
+
+func (FakeEmbedder) Model() string {
+	return "fake"
+}
+
+func (FakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, FakeDimensions)
+		for _, word := range fakeWordPattern.FindAllString(strings.ToLower(text), -1) {
+			hash := fnv.New32a()
+			hash.Write([]byte(word))
+			vector[hash.Sum32()%FakeDimensions]++
+		}
+		normalize(vector)
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
@@ -0,0 +1,140 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterProvider("ollama", func(cfg Config) (Embedder, error) {
+		cfg = cfg.withDefaults("nomic-embed-text", "http://localhost:11434", "")
+		return newHTTPEmbedder(cfg, "/api/embed", decodeOllama), nil
+	})
+	RegisterProvider("openai", openAICompatibleProvider("text-embedding-3-small", "https://api.openai.com/v1", "OPENAI_API_KEY"))
+	// llama.cpp's server speaks the openai embeddings api
+	RegisterProvider("llamacpp", openAICompatibleProvider("", "http://localhost:8080/v1", ""))
+}
+
+// openAICompatibleProvider returns a Provider for any server implementing the OpenAI embeddings api
+func openAICompatibleProvider(defaultModel, defaultBaseURL, apiKeyEnv string) Provider {
+	return func(cfg Config) (Embedder, error) {
+		cfg = cfg.withDefaults(defaultModel, defaultBaseURL, apiKeyEnv)
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("EMBEDDING_MODEL is required for the %s provider", cfg.Provider)
+		}
+		return newHTTPEmbedder(cfg, "/embeddings", decodeOpenAI), nil
+	}
+}
+
+// httpEmbedder posts {model, input} to an embeddings endpoint, which both ollama and openai accept
+type httpEmbedder struct {
+	cfg    Config
+	url    string
+	decode func(body []byte, n int) ([][]float32, error)
+	client *http.Client
+}
+
+func newHTTPEmbedder(cfg Config, path string, decode func(body []byte, n int) ([][]float32, error)) *httpEmbedder {
+	return &httpEmbedder{
+		cfg:    cfg,
+		url:    strings.TrimSuffix(cfg.BaseURL, "/") + path,
+		decode: decode,
+		client: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+func (h *httpEmbedder) Model() string {
+	return h.cfg.modelName()
+}
+
+func (h *httpEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	payload, err := json.Marshal(embedRequest{Model: h.cfg.Model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.cfg.APIKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s embeddings: %w", h.cfg.Provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s embeddings returned %s: %s", h.cfg.Provider, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	vectors, err := h.decode(body, len(texts))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s embeddings: %w", h.cfg.Provider, err)
+	}
+	for i := range vectors {
+		normalize(vectors[i])
+	}
+	return vectors, nil
+}
+
+func decodeOllama(body []byte, n int) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != n {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", n, len(resp.Embeddings))
+	}
+	return resp.Embeddings, nil
+}
+
+func decodeOpenAI(body []byte, n int) ([][]float32, error) {
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, n)
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= n {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("missing embedding %d", i)
+		}
+	}
+	return vectors, nil
+}
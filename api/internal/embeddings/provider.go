@@ -0,0 +1,123 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const DefaultProvider = "ollama"
+
+// Embedder turns text into vectors whose cosine similarity reflects how close the texts are in meaning
+type Embedder interface {
+	// Embed returns a vector for each text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model identifies the provider and model, vectors from different models can't be compared
+	Model() string
+}
+
+// Config selects which provider backs an Embedder and how to reach it.
+// Empty values are filled in with the provider's own defaults.
+type Config struct {
+	Provider string
+	Model    string
+	BaseURL  string
+	APIKey   string
+}
+
+// ConfigFromEnv reads the embedder config from the environment:
+//
+//	EMBEDDING_PROVIDER  registered provider name (default "ollama")
+//	EMBEDDING_MODEL     model name passed to the provider
+//	EMBEDDING_BASE_URL  base url of the provider's server
+//	EMBEDDING_API_KEY   api key, falls back to the provider specific key (e.g. OPENAI_API_KEY)
+func ConfigFromEnv() Config {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("EMBEDDING_PROVIDER")))
+	if provider == "" {
+		provider = DefaultProvider
+	}
+
+	return Config{
+		Provider: provider,
+		Model:    os.Getenv("EMBEDDING_MODEL"),
+		BaseURL:  os.Getenv("EMBEDDING_BASE_URL"),
+		APIKey:   os.Getenv("EMBEDDING_API_KEY"),
+	}
+}
+
+// Provider creates an Embedder
+type Provider func(cfg Config) (Embedder, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// NewEmbedder creates an Embedder for the provider configured in the environment
+func NewEmbedder() (Embedder, error) {
+	return NewEmbedderWithConfig(ConfigFromEnv())
+}
+
+func NewEmbedderWithConfig(cfg Config) (Embedder, error) {
+	provider, err := lookupProvider(cfg.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return provider(cfg)
+}
+
+// RegisterProvider makes a provider available by name. Registering the same name twice replaces the previous provider.
+func RegisterProvider(name string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[strings.ToLower(name)] = provider
+}
+
+// Providers returns the sorted names of all registered providers
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return providersLocked()
+}
+
+func lookupProvider(name string) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	provider, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown embedding provider %q (available: %s)", name, strings.Join(providersLocked(), ", "))
+	}
+	return provider, nil
+}
+
+func providersLocked() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withDefaults fills in any empty config values
+func (c Config) withDefaults(model, baseURL, apiKeyEnv string) Config {
+	if c.Model == "" {
+		c.Model = model
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = baseURL
+	}
+	if c.APIKey == "" && apiKeyEnv != "" {
+		c.APIKey = os.Getenv(apiKeyEnv)
+	}
+	return c
+}
+
+// modelName is what an embedder's Model returns, the provider and model together
+func (c Config) modelName() string {
+	return c.Provider + "/" + c.Model
+}
@@ -0,0 +1,226 @@
+package embeddings
+
+import (
+	"api/internal/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Collection holds the vectors, one per summary or note and model
+const Collection = "sermon_embeddings"
+
+// document is a piece of a sermon that gets its own vector
+type document struct {
+	source   string
+	recordId string
+	text     string
+	hash     string
+}
+
+// documents collects the sermon's summary and notes
+func documents(app core.App, sermon *core.Record) ([]document, error) {
+	docs := []document{}
+	add := func(source string, recordId string, parts ...string) {
+		text := joinText(parts...)
+		if text == "" {
+			return
+		}
+		hash := sha256.Sum256([]byte(text))
+		docs = append(docs, document{source: source, recordId: recordId, text: text, hash: hex.EncodeToString(hash[:])})
+	}
+
+	if sermon.GetString("summary") != "" {
+		add(models.EmbeddingSourceSummary, sermon.Id, sermon.GetString("title"), sermon.GetString("summary"))
+	}
+
+	details, err := app.FindAllRecords("sermon_details", dbx.HashExp{"sermon_id": sermon.Id})
+	if err != nil {
+		return nil, err
+	}
+	for _, detail := range details {
+		add(models.EmbeddingSourceNote, detail.Id, detail.GetString("title"), detail.GetString("description"))
+	}
+	return docs, nil
+}
+
+// Sync brings the sermon's vectors up to date with its summary and notes. Only text that changed since
+// it was last embedded is sent to the embedder, and vectors from other models or of notes that are gone
+// are removed.
+func Sync(ctx context.Context, app core.App, embedder Embedder, sermonId string) error {
+	sermon, err := app.FindRecordById("sermons", sermonId)
+	if err != nil {
+		return err
+	}
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		return err
+	}
+	docs, err := documents(app, sermon)
+	if err != nil {
+		return err
+	}
+	existing, err := app.FindAllRecords(collection, dbx.HashExp{"sermon_id": sermonId})
+	if err != nil {
+		return err
+	}
+
+	model := embedder.Model()
+	current := map[string]*core.Record{}
+	for _, record := range existing {
+		if record.GetString("model") == model {
+			current[record.GetString("record_id")] = record
+		}
+	}
+
+	stale := []document{}
+	texts := []string{}
+	for _, doc := range docs {
+		if record := current[doc.recordId]; record != nil && record.GetString("content_hash") == doc.hash {
+			continue
+		}
+		stale = append(stale, doc)
+		texts = append(texts, doc.text)
+	}
+
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(stale) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(stale), len(vectors))
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		kept := map[string]bool{}
+		for _, doc := range docs {
+			kept[doc.recordId] = true
+		}
+		for _, record := range existing {
+			if record.GetString("model") != model || !kept[record.GetString("record_id")] {
+				if err := txApp.Delete(record); err != nil {
+					return err
+				}
+			}
+		}
+
+		for i, doc := range stale {
+			record := current[doc.recordId]
+			if record == nil {
+				record = core.NewRecord(collection)
+				record.Set("sermon_id", sermonId)
+				record.Set("record_id", doc.recordId)
+				record.Set("model", model)
+			}
+			record.Set("source", doc.source)
+			record.Set("content_hash", doc.hash)
+			record.Set("vector", vectors[i])
+			if err := txApp.Save(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Match is the closest summary or note of a sermon to a query vector
+type Match struct {
+	SermonId   string  `json:"sermon_id"`
+	Source     string  `json:"source"`
+	RecordId   string  `json:"record_id"`
+	Similarity float64 `json:"similarity"` // Cosine similarity, 1 is identical
+}
+
+// Nearest finds the sermons closest to the vector by their best matching summary or note, most similar
+// first, leaving out any that are unrelated. It compares against every stored vector of the model, which is plenty fast at the size of a
+// church's archive. Only complete sermons are searched unless includeIncomplete is set, deleted ones never are.
+func Nearest(app core.App, model string, vector []float32, limit int, includeIncomplete bool) ([]Match, error) {
+	visible := dbx.HashExp{"s.status": models.SermonStatusComplete}
+	if includeIncomplete {
+		visible = nil
+	}
+	rows := []models.SermonEmbedding{}
+	err := app.DB().
+		Select("e.sermon_id", "e.source", "e.record_id", "e.vector").
+		From(Collection+" e").
+		InnerJoin("sermons s", dbx.NewExp("s.id = e.sermon_id")).
+		Where(dbx.HashExp{"e.model": model}).
+		AndWhere(dbx.Not(dbx.HashExp{"s.status": models.SermonStatusDeleted})).
+		AndWhere(visible).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	best := map[string]Match{}
+	for _, row := range rows {
+		stored := []float32{}
+		if err := json.Unmarshal(row.Vector, &stored); err != nil {
+			return nil, err
+		}
+		similarity := Similarity(vector, stored)
+		if similarity <= 0 {
+			continue
+		}
+		if match, ok := best[row.SermonId]; ok && match.Similarity >= similarity {
+			continue
+		}
+		best[row.SermonId] = Match{SermonId: row.SermonId, Source: row.Source, RecordId: row.RecordId, Similarity: similarity}
+	}
+
+	matches := make([]Match, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].SermonId < matches[j].SermonId
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// Summaries loads the vectors the model computed for sermon summaries, keyed by sermon id
+func Summaries(app core.App, model string) (map[string][]float32, error) {
+	rows := []models.SermonEmbedding{}
+	err := app.DB().
+		Select("sermon_id", "vector").
+		From(Collection).
+		Where(dbx.HashExp{"model": model, "source": models.EmbeddingSourceSummary}).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make(map[string][]float32, len(rows))
+	for _, row := range rows {
+		vector := []float32{}
+		if err := json.Unmarshal(row.Vector, &vector); err != nil {
+			return nil, err
+		}
+		vectors[row.SermonId] = vector
+	}
+	return vectors, nil
+}
+
+// joinText joins the non-empty parts of a document
+func joinText(parts ...string) string {
+	kept := []string{}
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
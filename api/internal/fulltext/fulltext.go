@@ -0,0 +1,178 @@
+package fulltext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Table is the FTS5 table holding the searchable text of every sermon
+const Table = "sermon_search"
+
+// Sources are the kinds of record a search hit can come from
+const (
+	SourceSermon     = "sermon"
+	SourceNote       = "note"
+	SourceQuestion   = "question"
+	SourceTranscript = "transcript"
+)
+
+// Collections are the collections whose records are searchable
+var Collections = []string{"sermons", "sermon_details", "sermon_questions", "sermon_transcripts"}
+
+// document is a record's row in the search table
+type document struct {
+	SermonId string
+	Source   string
+	RecordId string
+	StartMs  int64
+	Title    string
+	Body     string
+}
+
+// documentFor builds the searchable text of a record, false if the record isn't searchable
+func documentFor(record *core.Record) (document, bool) {
+	doc := document{SermonId: record.GetString("sermon_id"), RecordId: record.Id}
+
+	switch record.Collection().Name {
+	case "sermons":
+		doc.SermonId = record.Id
+		doc.Source = SourceSermon
+		doc.Title = record.GetString("title")
+		doc.Body = joinText(record.GetString("summary"), record.GetString("speaker"))
+	case "sermon_details":
+		doc.Source = SourceNote
+		doc.Title = record.GetString("title")
+		doc.Body = joinText(
+			record.GetString("description"),
+			record.GetString("key_verse"),
+			strings.ReplaceAll(record.GetString("relevant_verses"), "|", " "),
+		)
+	case "sermon_questions":
+		doc.Source = SourceQuestion
+		doc.Title = record.GetString("title")
+		doc.Body = record.GetString("description")
+	case "sermon_transcripts":
+		doc.Source = SourceTranscript
+		doc.StartMs = int64(record.GetInt("start_ms"))
+		doc.Body = record.GetString("text")
+	default:
+		return document{}, false
+	}
+
+	return doc, doc.SermonId != ""
+}
+
+// Index adds the record to the search table, replacing what was indexed for it before
+func Index(app core.App, record *core.Record) error {
+	doc, ok := documentFor(record)
+	if !ok {
+		return nil
+	}
+
+	if err := remove(app, dbx.HashExp{"record_id": record.Id}); err != nil {
+		return err
+	}
+
+	_, err := app.DB().Insert(Table, dbx.Params{
+		"sermon_id": doc.SermonId,
+		"source":    doc.Source,
+		"record_id": doc.RecordId,
+		"start_ms":  doc.StartMs,
+		"title":     doc.Title,
+		"body":      doc.Body,
+	}).Execute()
+	return err
+}
+
+// Remove takes the record out of the search table, for a sermon along with everything that belongs to it
+func Remove(app core.App, record *core.Record) error {
+	if record.Collection().Name == "sermons" {
+		return remove(app, dbx.HashExp{"sermon_id": record.Id})
+	}
+	return remove(app, dbx.HashExp{"record_id": record.Id})
+}
+
+func remove(app core.App, where dbx.Expression) error {
+	_, err := app.DB().Delete(Table, where).Execute()
+	return err
+}
+
+// Reindex rebuilds the search table from scratch
+func Reindex(app core.App) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		if _, err := txApp.DB().Delete(Table, nil).Execute(); err != nil {
+			return err
+		}
+
+		for _, collection := range Collections {
+			records, err := txApp.FindAllRecords(collection)
+			if err != nil {
+				return err
+			}
+			for _, record := range records {
+				if err := Index(txApp, record); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// joinText joins the non-empty parts of a document's body
+func joinText(parts ...string) string {
+	kept := []string{}
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+var (
+	// a "quoted phrase" or a single word
+	termPattern = regexp.MustCompile(`"([^"]*)"|[\p{L}\p{N}]+`)
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+// matchExpression turns what a user typed into an FTS5 query that can't be a syntax error: every word
+// or "quoted phrase" has to match, and the last word can be the start of a longer one
+func matchExpression(q string) string {
+	terms := []string{}
+	prefix := false
+	for _, match := range termPattern.FindAllStringSubmatch(q, -1) {
+		if strings.HasPrefix(match[0], `"`) {
+			words := wordPattern.FindAllString(match[1], -1)
+			if len(words) > 0 {
+				terms = append(terms, `"`+strings.Join(words, " ")+`"`)
+				prefix = false
+			}
+			continue
+		}
+		terms = append(terms, `"`+match[0]+`"`)
+		prefix = true
+	}
+
+	if prefix {
+		terms[len(terms)-1] += "*"
+	}
+	return strings.Join(terms, " ")
+}
+
+// markers FTS5 wraps matches in for snippets, replaced with <mark> once the snippet is escaped
+const (
+	markOpen  = "\uE000"
+	markClose = "\uE001"
+)
+
+var markReplacer = strings.NewReplacer(markOpen, "<mark>", markClose, "</mark>")
+
+// highlight escapes a snippet for HTML and turns the match markers into <mark> tags
+func highlight(snippet string) string {
+	return markReplacer.Replace(html.EscapeString(snippet))
+}
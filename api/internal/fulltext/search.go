@@ -0,0 +1,114 @@
+package fulltext
+
+import (
+	"api/internal/models"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+	// how many tokens of context a snippet has around the match
+	snippetTokens = 16
+)
+
+// Query is a search over the sermons
+type Query struct {
+	Text    string
+	Page    int
+	PerPage int
+	// IncludeIncomplete also searches sermons that haven't finished analysis, for admins.
+	// Deleted sermons are never searched.
+	IncludeIncomplete bool
+}
+
+// Hit is the best match within a single sermon
+type Hit struct {
+	SermonId string  `json:"sermon_id" db:"sermon_id"`
+	Source   string  `json:"source" db:"source"`       // Which kind of record matched, see the Source constants
+	RecordId string  `json:"record_id" db:"record_id"` // The record that matched
+	StartMs  int64   `json:"start_ms" db:"start_ms"`   // Where in the recording, for transcript matches
+	Snippet  string  `json:"snippet" db:"snippet"`     // HTML, with the matches in <mark> tags
+	Rank     float64 `json:"rank" db:"rank"`           // BM25, lower is better
+	Matches  int     `json:"matches" db:"matches"`     // How many of the sermon's records matched
+}
+
+// Results is a page of hits, one per sermon, best first
+type Results struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"perPage"`
+	TotalItems int   `json:"totalItems"`
+	TotalPages int   `json:"totalPages"`
+	Items      []Hit `json:"items"`
+}
+
+// Search ranks sermons by their best matching record with BM25, titles counting for more than
+// the text under them
+func Search(app core.App, query Query) (Results, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PerPage < 1 {
+		query.PerPage = DefaultPerPage
+	}
+	query.PerPage = min(query.PerPage, MaxPerPage)
+
+	results := Results{Page: query.Page, PerPage: query.PerPage, Items: []Hit{}}
+
+	match := matchExpression(query.Text)
+	if match == "" {
+		return results, nil
+	}
+
+	visible := fmt.Sprintf("s.status = '%s'", models.SermonStatusComplete)
+	if query.IncludeIncomplete {
+		visible = fmt.Sprintf("s.status != '%s'", models.SermonStatusDeleted)
+	}
+	hits := fmt.Sprintf(`
+		SELECT
+			%[1]s.sermon_id, %[1]s.source, %[1]s.record_id, %[1]s.start_ms,
+			snippet(%[1]s, -1, {:open}, {:close}, '…', %[2]d) AS snippet,
+			bm25(%[1]s, 0, 0, 0, 0, 5.0, 1.0) AS rank
+		FROM %[1]s
+		INNER JOIN sermons s ON s.id = %[1]s.sermon_id
+		WHERE %[1]s MATCH {:match} AND %[3]s`, Table, snippetTokens, visible)
+	params := dbx.Params{"match": match, "open": markOpen, "close": markClose}
+
+	err := app.DB().
+		NewQuery(fmt.Sprintf("SELECT COUNT(DISTINCT sermon_id) FROM (%s)", hits)).
+		Bind(params).
+		Row(&results.TotalItems)
+	if err != nil {
+		return results, err
+	}
+	results.TotalPages = (results.TotalItems + query.PerPage - 1) / query.PerPage
+
+	params["limit"] = query.PerPage
+	params["offset"] = (query.Page - 1) * query.PerPage
+	err = app.DB().
+		NewQuery(fmt.Sprintf(`
+			WITH hits AS (%s), best AS (
+				SELECT *,
+					ROW_NUMBER() OVER (PARTITION BY sermon_id ORDER BY rank) AS n,
+					COUNT(*) OVER (PARTITION BY sermon_id) AS matches
+				FROM hits
+			)
+			SELECT sermon_id, source, record_id, start_ms, snippet, rank, matches
+			FROM best
+			WHERE n = 1
+			ORDER BY rank, sermon_id
+			LIMIT {:limit} OFFSET {:offset}`, hits)).
+		Bind(params).
+		All(&results.Items)
+	if err != nil {
+		return results, err
+	}
+
+	for i := range results.Items {
+		results.Items[i].Snippet = highlight(results.Items[i].Snippet)
+	}
+	return results, nil
+}
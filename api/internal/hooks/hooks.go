@@ -1,11 +1,35 @@
 package hooks
 
 import (
-	"github.com/pocketbase/pocketbase"
+	"api/internal/fulltext"
+
+	"github.com/pocketbase/pocketbase/core"
 )
 
 // ConfigureHooks sets up all PocketBase event hooks
-func ConfigureHooks(app *pocketbase.PocketBase) {
+func ConfigureHooks(app core.App) {
 	// Hook into user creation to set default role
 	app.OnRecordCreate("users").BindFunc(setNewUserRole)
+
+	// Hook into analysis job creation to queue the job
+	app.OnRecordCreate("analysis_jobs").BindFunc(setNewJobStatus)
+
+	// Hook into analysis job creation to make sure there's something to analyze
+	app.OnRecordCreate("analysis_jobs").BindFunc(requireJobAudio)
+
+	// Hook into analysis job creation and updates to check the clip offsets
+	app.OnRecordCreate("analysis_jobs").BindFunc(validateJobClip)
+	app.OnRecordUpdate("analysis_jobs").BindFunc(validateJobClip)
+
+	// Hook into sermon creation and updates to link the sermon to its speaker
+	app.OnRecordCreate("sermons").BindFunc(linkSermonSpeaker)
+	app.OnRecordUpdate("sermons").BindFunc(linkSermonSpeaker)
+
+	// Hook into speaker updates to keep their name on their sermons
+	app.OnRecordUpdate("speakers").BindFunc(renameSpeakerSermons)
+
+	// Hook into changes to sermons and everything searchable under them to keep the search table in sync
+	app.OnRecordAfterCreateSuccess(fulltext.Collections...).BindFunc(indexSearchRecord)
+	app.OnRecordAfterUpdateSuccess(fulltext.Collections...).BindFunc(indexSearchRecord)
+	app.OnRecordAfterDeleteSuccess(fulltext.Collections...).BindFunc(removeSearchRecord)
 }
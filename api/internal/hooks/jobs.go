@@ -0,0 +1,52 @@
+package hooks
+
+import (
+	"api/internal/audio"
+	"api/internal/models"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func setNewJobStatus(e *core.RecordEvent) error {
+	// New jobs always start out queued, whatever the client sent
+	e.Record.Set("status", models.JobStatusQueued)
+	if e.Record.GetString("job_type") == "" {
+		e.Record.Set("job_type", models.JobTypeFull)
+	}
+
+	return e.Next()
+}
+
+func requireJobAudio(e *core.RecordEvent) error {
+	// A job needs a url to download, unless its sermon has an uploaded recording
+	if e.Record.GetString("audio_url") != "" {
+		return e.Next()
+	}
+
+	sermon, err := e.App.FindRecordById("sermons", e.Record.GetString("sermon_id"))
+	if err == nil && sermon.GetString("audio_file") != "" {
+		return e.Next()
+	}
+
+	return validation.Errors{
+		"audio_url": validation.NewError("validation_missing_audio", "Provide an audio url or upload an audio file to the sermon"),
+	}
+}
+
+func validateJobClip(e *core.RecordEvent) error {
+	// Offsets have to make a window of the recording, the field pattern only checks their format
+	start, end := e.Record.GetString("start_offset"), e.Record.GetString("end_offset")
+	if _, err := audio.ParseOffset(start); err != nil {
+		return validation.Errors{
+			"start_offset": validation.NewError("validation_invalid_offset", err.Error()),
+		}
+	}
+	if _, err := audio.ParseClip(start, end); err != nil {
+		return validation.Errors{
+			"end_offset": validation.NewError("validation_invalid_clip", err.Error()),
+		}
+	}
+
+	return e.Next()
+}
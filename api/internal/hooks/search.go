@@ -0,0 +1,32 @@
+package hooks
+
+import (
+	"api/internal/fulltext"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// indexSearchRecord keeps the record's text in the search table up to date. The record is already
+// saved by now, so a failure is logged rather than returned.
+func indexSearchRecord(e *core.RecordEvent) error {
+	if err := e.Next(); err != nil {
+		return err
+	}
+
+	if err := fulltext.Index(e.App, e.Record); err != nil {
+		e.App.Logger().Error("Search: Failed to index record", "collection", e.Record.Collection().Name, "record", e.Record.Id, "error", err)
+	}
+	return nil
+}
+
+// removeSearchRecord takes a deleted record's text out of the search table
+func removeSearchRecord(e *core.RecordEvent) error {
+	if err := e.Next(); err != nil {
+		return err
+	}
+
+	if err := fulltext.Remove(e.App, e.Record); err != nil {
+		e.App.Logger().Error("Search: Failed to remove record", "collection", e.Record.Collection().Name, "record", e.Record.Id, "error", err)
+	}
+	return nil
+}
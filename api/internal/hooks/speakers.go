@@ -0,0 +1,53 @@
+package hooks
+
+import (
+	"api/internal/speakers"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func linkSermonSpeaker(e *core.RecordEvent) error {
+	// A sermon given a speaker takes their name. A sermon given only a name is linked to the speaker it
+	// refers to, who is created if they're new, so clients can keep sending the name.
+	sermon, original := e.Record, e.Record.Original()
+	speakerId := sermon.GetString("speaker_id")
+
+	switch {
+	case speakerId != "" && speakerId != original.GetString("speaker_id"):
+		if speaker, err := e.App.FindRecordById(speakers.Collection, speakerId); err == nil {
+			speakers.Link(sermon, speaker)
+		}
+	case sermon.GetString("speaker") != original.GetString("speaker") || (speakerId == "" && sermon.GetString("speaker") != ""):
+		speaker, err := speakers.Resolve(e.App, sermon.GetString("speaker"))
+		if err != nil {
+			return err
+		}
+		speakers.Link(sermon, speaker)
+	}
+
+	return e.Next()
+}
+
+func renameSpeakerSermons(e *core.RecordEvent) error {
+	// Renaming a speaker renames them on their sermons too
+	renamed := e.Record.GetString("name") != e.Record.Original().GetString("name")
+	if err := e.Next(); err != nil {
+		return err
+	}
+	if !renamed {
+		return nil
+	}
+
+	sermons, err := e.App.FindAllRecords("sermons", dbx.HashExp{"speaker_id": e.Record.Id})
+	if err != nil {
+		return err
+	}
+	for _, sermon := range sermons {
+		speakers.Link(sermon, e.Record)
+		if err := e.App.Save(sermon); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,14 +3,20 @@ package jobs
 import (
 	"api/internal/ai"
 	"api/internal/models"
+	"api/internal/scripture"
+	"errors"
+	"strings"
 
-	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
-func SermonAnalysisJob(app *pocketbase.PocketBase) {
-	sermonJobs := []models.SermonAnalysisJob{}
-	err := app.DB().NewQuery("SELECT * FROM analysis_jobs WHERE sermon_id IN (SELECT id FROM sermons WHERE status = 'created')").All(&sermonJobs)
+// SermonAnalysisJob runs every due analysis job one after another
+func SermonAnalysisJob(app core.App) {
+	ReclaimExpiredLeases(app)
+
+	sermonJobs, err := dueJobs(app)
 	if err != nil {
 		app.Logger().Error("SermonAnalysisJob: Error getting sermon jobs", "error", err.Error())
 		return
@@ -22,40 +28,122 @@ func SermonAnalysisJob(app *pocketbase.PocketBase) {
 
 	app.Logger().Info("SermonAnalysisJob: Found sermon jobs", "count", len(sermonJobs))
 	for _, job := range sermonJobs {
-		analyzer, err := ai.NewAnalyzer(job, app.Logger())
+		runJob(app, job)
+	}
+}
+
+// dueJobs returns the queued jobs that are ready to run, oldest first
+func dueJobs(app core.App) ([]models.SermonAnalysisJob, error) {
+	sermonJobs := []models.SermonAnalysisJob{}
+	err := app.DB().NewQuery("SELECT * FROM analysis_jobs WHERE status = {:status} AND (next_attempt_at = '' OR next_attempt_at <= {:now}) AND sermon_id IN (SELECT id FROM sermons WHERE status IN ('created', 'complete')) ORDER BY created").
+		Bind(dbx.Params{"status": models.JobStatusQueued, "now": types.NowDateTime().String()}).
+		All(&sermonJobs)
+
+	return sermonJobs, err
+}
+
+// runJob claims a job and analyzes its sermon. It does nothing if another runner claimed the job first.
+func runJob(app core.App, job models.SermonAnalysisJob) {
+	claimed, err := claimJob(app, job)
+	if err != nil {
+		app.Logger().Error("SermonAnalysisJob: Error claiming job", "job", job.Id, "error", err.Error())
+		return
+	}
+	if !claimed {
+		// another run got to it first
+		return
+	}
+	job.Attempts++
+	startedAt := types.NowDateTime()
+
+	analyzer, err := ai.NewAnalyzer(job, app.Logger())
+	if err != nil {
+		app.Logger().Error("SermonAnalysisJob: Error creating analyzer", "error", err.Error())
+		finishAttempt(app, job, startedAt, err)
+		return
+	}
+
+	stopHeartbeat := startHeartbeat(app, job)
+	result, err := analyze(app, analyzer, job)
+	stopHeartbeat()
+	if errors.Is(err, errLeaseLost) {
+		app.Logger().Warn("SermonAnalysisJob: Lost lease before storing transcript, discarding it", "job", job.Id)
+		return
+	}
+	if err != nil {
+		app.Logger().Error("SermonAnalysisJob: Error analyzing sermon", "error", err.Error(), "retryable", ai.IsRetryable(err))
+		finishAttempt(app, job, startedAt, err)
+		return
+	}
+
+	err = upsertRecords(app, job, result)
+	if errors.Is(err, errLeaseLost) {
+		app.Logger().Warn("SermonAnalysisJob: Lost lease before storing result, discarding it", "job", job.Id)
+		return
+	}
+	if err != nil {
+		app.Logger().Error("SermonAnalysisJob: Error storing result into DB", "error", err.Error())
+		finishAttempt(app, job, startedAt, err)
+		return
+	}
+
+	app.Logger().Info("SermonAnalysisJob: Analysis complete", "job", job.Id, "job_type", job.JobType)
+	if err := finishAttempt(app, job, startedAt, nil); err != nil {
+		return
+	}
+	embedSermon(app, job)
+	relateSermon(app, job)
+}
+
+// analyze runs the analysis on the sermon's stored transcript, transcribing the recording
+// first when there isn't one yet or the job asks for a new one
+func analyze(app core.App, analyzer ai.Analyzer, job models.SermonAnalysisJob) (ai.AnalysisResult, error) {
+	transcript := ai.Transcript{}
+	if job.JobType != models.JobTypeTranscript {
+		segments, err := loadTranscript(app, job.SermonId)
 		if err != nil {
-			app.Logger().Error("SermonAnalysisJob: Error creating analyzer", "error", err.Error())
-			setStatus(app, job, models.SermonStatusError)
-			continue
+			return ai.AnalysisResult{}, err
 		}
+		transcript.Segments = segments
+	}
 
-		setStatus(app, job, models.SermonStatusPending)
-		result, err := analyzer.AnalyzeSermon(job)
+	if len(transcript.Segments) == 0 {
+		source, err := audioSource(app, job)
 		if err != nil {
-			app.Logger().Error("SermonAnalysisJob: Error analyzing sermon", "error", err.Error())
-			setStatus(app, job, models.SermonStatusError)
-			continue
+			return ai.AnalysisResult{}, err
 		}
 
-		err = upsertRecords(app, job, result)
+		transcript, err = analyzer.Transcribe(job, source)
 		if err != nil {
-			app.Logger().Error("SermonAnalysisJob: Error storing result into DB", "error", err.Error())
-			setStatus(app, job, models.SermonStatusError)
-			continue
+			return ai.AnalysisResult{}, err
 		}
 
-		app.Logger().Info("SermonAnalysisJob: Analysis complete", "job", job.Id)
-		setStatus(app, job, models.SermonStatusComplete)
+		// stored straight away, so a retry of the analysis doesn't have to transcribe again
+		if err := storeTranscript(app, job, transcript); err != nil {
+			return ai.AnalysisResult{}, err
+		}
+		app.Logger().Info("SermonAnalysisJob: Stored transcript", "job", job.Id, "segments", len(transcript.Segments))
+	} else {
+		app.Logger().Info("SermonAnalysisJob: Using stored transcript", "job", job.Id, "segments", len(transcript.Segments))
 	}
+
+	return analyzer.AnalyzeTranscript(job, transcript)
 }
 
-func setStatus(app *pocketbase.PocketBase, job models.SermonAnalysisJob, status string) error {
+// setStatus moves the sermon along with its job. A sermon that's already complete is being re-run and
+// keeps serving its current analysis, and a deleted sermon stays deleted.
+func setStatus(app core.App, job models.SermonAnalysisJob, status string) error {
 	record, err := app.FindRecordById("sermons", job.SermonId)
 	if err != nil {
 		app.Logger().Error("SermonAnalysisJob: ERROR: Unable to set status of sermon job", "job", job.Id, "error", err.Error())
 		return err
 	}
 
+	current := record.GetString("status")
+	if current == models.SermonStatusComplete || current == models.SermonStatusDeleted {
+		return nil
+	}
+
 	record.Set("status", status)
 
 	err = app.Save(record)
@@ -67,53 +155,120 @@ func setStatus(app *pocketbase.PocketBase, job models.SermonAnalysisJob, status
 	return nil
 }
 
-func upsertRecords(app *pocketbase.PocketBase, job models.SermonAnalysisJob, result ai.AnalysisResult) error {
-	sermon, err := app.FindRecordById("sermons", job.SermonId)
-	if err != nil {
-		return err
-	}
+// upsertRecords stores the parts of the analysis the job is responsible for in a single transaction,
+// replacing what was there before, so running the same job twice still leaves exactly one result
+func upsertRecords(app core.App, job models.SermonAnalysisJob, result ai.AnalysisResult) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		// if our lease was reclaimed another worker owns the job now, let its result win
+		if err := checkLease(txApp, job); err != nil {
+			return err
+		}
 
-	sermon.Set("summary", result.Summary)
-	err = app.Save(sermon)
-	if err != nil {
-		return err
-	}
+		if job.Replaces(models.JobTypeSummary) {
+			sermon, err := txApp.FindRecordById("sermons", job.SermonId)
+			if err != nil {
+				return err
+			}
 
-	detailsCollection, err := app.FindCollectionByNameOrId("sermon_details")
-	if err != nil {
-		return err
-	}
+			sermon.Set("summary", result.Summary)
+			sermon.Set("topics", cleanTopics(result.Topics))
+			err = txApp.Save(sermon)
+			if err != nil {
+				return err
+			}
+		}
 
-	for i, detail := range result.Details {
-		detailRecord := core.NewRecord(detailsCollection)
-		detailRecord.Set("sermon_id", job.SermonId)
-		detailRecord.Set("title", detail.Title)
-		detailRecord.Set("description", detail.Description)
-		detailRecord.Set("key_verse", detail.KeyVerse)
-		detailRecord.Set("relevant_verses", detail.RelevantVerses)
-		detailRecord.Set("order", i)
-		err = app.Save(detailRecord)
-		if err != nil {
-			return err
+		if job.Replaces(models.JobTypeNotes) {
+			for _, collection := range []string{"sermon_verse_refs", "sermon_warnings", "sermon_details"} {
+				if err := deleteSermonRecords(txApp, collection, job.SermonId); err != nil {
+					return err
+				}
+			}
+
+			detailsCollection, err := txApp.FindCollectionByNameOrId("sermon_details")
+			if err != nil {
+				return err
+			}
+
+			for i, detail := range result.Details {
+				// the model's references are stored canonically, and the ones that don't exist are left out
+				keyVerses := checkVerses("key_verse", models.VerseRefKey, detail.KeyVerse)
+				relevantVerses := checkVerses("relevant_verses", models.VerseRefRelevant, detail.RelevantVerses)
+
+				detailRecord := core.NewRecord(detailsCollection)
+				detailRecord.Set("sermon_id", job.SermonId)
+				detailRecord.Set("title", detail.Title)
+				detailRecord.Set("description", detail.Description)
+				detailRecord.Set("key_verse", scripture.FormatPassage(keyVerses.refs))
+				detailRecord.Set("relevant_verses", scripture.Format(relevantVerses.refs))
+				detailRecord.Set("order", i)
+				err = txApp.Save(detailRecord)
+				if err != nil {
+					return err
+				}
+
+				for _, verses := range []noteVerses{keyVerses, relevantVerses} {
+					if err := saveVerses(txApp, job, detailRecord.Id, verses); err != nil {
+						return err
+					}
+				}
+			}
 		}
-	}
 
-	questionsCollection, err := app.FindCollectionByNameOrId("sermon_questions")
+		if job.Replaces(models.JobTypeQuestions) {
+			err := deleteSermonRecords(txApp, "sermon_questions", job.SermonId)
+			if err != nil {
+				return err
+			}
+
+			questionsCollection, err := txApp.FindCollectionByNameOrId("sermon_questions")
+			if err != nil {
+				return err
+			}
+
+			for i, question := range result.Questions {
+				questionRecord := core.NewRecord(questionsCollection)
+				questionRecord.Set("sermon_id", job.SermonId)
+				questionRecord.Set("title", question.Title)
+				questionRecord.Set("description", question.Description)
+				questionRecord.Set("order", i)
+				err = txApp.Save(questionRecord)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// deleteSermonRecords removes every record in the collection belonging to the sermon
+func deleteSermonRecords(app core.App, collection string, sermonId string) error {
+	records, err := app.FindAllRecords(collection, dbx.HashExp{"sermon_id": sermonId})
 	if err != nil {
 		return err
 	}
 
-	for i, question := range result.Questions {
-		questionRecord := core.NewRecord(questionsCollection)
-		questionRecord.Set("sermon_id", job.SermonId)
-		questionRecord.Set("title", question.Title)
-		questionRecord.Set("description", question.Description)
-		questionRecord.Set("order", i)
-		err = app.Save(questionRecord)
-		if err != nil {
+	for _, record := range records {
+		if err := app.Delete(record); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// cleanTopics lowercases the model's topics and drops blanks and repeats, so topics can be compared between sermons
+func cleanTopics(topics []string) []string {
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, topic := range topics {
+		topic = strings.Join(strings.Fields(strings.ToLower(topic)), " ")
+		if topic != "" && !seen[topic] {
+			seen[topic] = true
+			cleaned = append(cleaned, topic)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"api/internal/ai"
+	"api/internal/audio"
+	"api/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ErrNoAudio means neither the job nor its sermon has a recording to analyze
+var ErrNoAudio = errors.New("job has no audio_url and its sermon has no audio_file")
+
+// audioSource picks where a job's recording comes from (its audio_url when set,
+// otherwise the file uploaded to its sermon) and normalizes it once it's fetched
+func audioSource(app core.App, job models.SermonAnalysisJob) (ai.AudioSource, error) {
+	source, err := rawAudioSource(app, job)
+	if err != nil {
+		return nil, err
+	}
+	return normalizedAudio(app, job, source)
+}
+
+func rawAudioSource(app core.App, job models.SermonAnalysisJob) (ai.AudioSource, error) {
+	if job.AudioURL != "" {
+		return ai.URLAudio(job.AudioURL), nil
+	}
+
+	sermon, err := app.FindRecordById("sermons", job.SermonId)
+	if err != nil {
+		return nil, err
+	}
+
+	name := sermon.GetString("audio_file")
+	if name == "" {
+		return nil, ErrNoAudio
+	}
+
+	return storedAudio(app, sermon.BaseFilesPath()+"/"+name), nil
+}
+
+// storedAudio is an AudioSource that copies an uploaded file out of the app's storage
+// (local pb_data or S3) into a temp file
+func storedAudio(app core.App, fileKey string) ai.AudioSource {
+	return func(ctx context.Context) (string, func(), error) {
+		fsys, err := app.NewFilesystem()
+		if err != nil {
+			return "", nil, err
+		}
+		defer fsys.Close()
+
+		reader, err := fsys.GetReader(fileKey)
+		if err != nil {
+			return "", nil, err
+		}
+		defer reader.Close()
+
+		tmpFile, err := os.CreateTemp("", "sermon-*"+path.Ext(fileKey))
+		if err != nil {
+			return "", nil, err
+		}
+		cleanup := func() { os.Remove(tmpFile.Name()) }
+
+		_, err = io.Copy(tmpFile, reader)
+		if closeErr := tmpFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return "", nil, err
+		}
+
+		return tmpFile.Name(), cleanup, nil
+	}
+}
+
+// normalizedAudio re-encodes what source fetches with ffmpeg, cut down to the job's clip, and
+// records the result's duration and size on the sermon. Without ffmpeg installed the recording
+// is passed through as is, unless the job needs it clipped.
+func normalizedAudio(app core.App, job models.SermonAnalysisJob, source ai.AudioSource) (ai.AudioSource, error) {
+	clip, err := audio.ParseClip(job.StartOffset, job.EndOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := audio.NormalizeConfigFromEnv()
+	if !cfg.Enabled && clip.IsZero() {
+		return source, nil
+	}
+	if err := cfg.Available(); err != nil {
+		if !clip.IsZero() {
+			return nil, fmt.Errorf("can't clip the recording: %w", err)
+		}
+		app.Logger().Warn("SermonAnalysisJob: Skipping audio normalization", "job", job.Id, "error", err.Error())
+		return source, nil
+	}
+
+	return func(ctx context.Context) (string, func(), error) {
+		path, cleanup, err := source(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		defer cleanup()
+
+		normalized, err := audio.Normalize(ctx, cfg, path, clip)
+		if err != nil {
+			return "", nil, err
+		}
+		app.Logger().Info("SermonAnalysisJob: Normalized audio", "job", job.Id, "duration", normalized.Duration.String(), "size", normalized.Size)
+
+		if err := recordAudio(app, job.SermonId, normalized); err != nil {
+			normalized.Remove()
+			return "", nil, err
+		}
+
+		return normalized.Path, func() { normalized.Remove() }, nil
+	}, nil
+}
+
+// recordAudio stores the duration and size of the audio that was analyzed on the sermon
+func recordAudio(app core.App, sermonId string, normalized *audio.Normalized) error {
+	sermon, err := app.FindRecordById("sermons", sermonId)
+	if err != nil {
+		return err
+	}
+
+	sermon.Set("audio_duration", normalized.Duration.Seconds())
+	sermon.Set("audio_size", normalized.Size)
+	return app.Save(sermon)
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"api/internal/embeddings"
+	"api/internal/models"
+	"context"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// how long computing a sermon's vectors may take
+const embedTimeout = 5 * time.Minute
+
+// embedSermon brings the sermon's vectors up to date once its analysis is stored. Search falls back to
+// keywords for sermons without them, so a failure doesn't fail the job.
+func embedSermon(app core.App, job models.SermonAnalysisJob) {
+	embedder, err := embeddings.NewEmbedder()
+	if err != nil {
+		app.Logger().Warn("SermonAnalysisJob: Error creating embedder", "job", job.Id, "error", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), embedTimeout)
+	defer cancel()
+	if err := embeddings.Sync(ctx, app, embedder, job.SermonId); err != nil {
+		app.Logger().Warn("SermonAnalysisJob: Error computing embeddings", "job", job.Id, "model", embedder.Model(), "error", err.Error())
+	}
+}
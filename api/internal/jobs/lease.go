@@ -0,0 +1,247 @@
+package jobs
+
+import (
+	"api/internal/models"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// leaseDuration is how long a worker owns a job without heartbeating before it can be reclaimed
+	leaseDuration = 5 * time.Minute
+	// heartbeatInterval must be comfortably shorter than leaseDuration
+	heartbeatInterval = time.Minute
+)
+
+// errLeaseLost means the job was reclaimed from this worker while it was still working on it
+var errLeaseLost = errors.New("lease is no longer held by this worker")
+
+// WorkerId identifies this process when it holds a lease on an analysis job
+var WorkerId = newWorkerId()
+
+func newWorkerId() string {
+	return fmt.Sprintf("%s-%d", workerHost(), os.Getpid())
+}
+
+// workerHost prefers the fly machine id since hostnames aren't stable across machine restarts
+func workerHost() string {
+	if machineId := os.Getenv("FLY_MACHINE_ID"); machineId != "" {
+		return machineId
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "unknown"
+	}
+	return host
+}
+
+// claimJob atomically moves a queued job to running under this worker's lease and marks its sermon as pending.
+// It reports false when another runner claimed the job first.
+func claimJob(app core.App, job models.SermonAnalysisJob) (bool, error) {
+	claimed := false
+	err := app.RunInTransaction(func(txApp core.App) error {
+		now := types.NowDateTime()
+		res, err := txApp.DB().Update("analysis_jobs", dbx.Params{
+			"status":           models.JobStatusRunning,
+			"attempts":         dbx.NewExp("attempts + 1"),
+			"worker_id":        WorkerId,
+			"claimed_at":       now.String(),
+			"heartbeat_at":     now.String(),
+			"lease_expires_at": now.Add(leaseDuration).String(),
+			"updated":          now.String(),
+		}, dbx.HashExp{"id": job.Id, "status": models.JobStatusQueued}).Execute()
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return nil
+		}
+
+		claimed = true
+		return setStatus(txApp, job, models.SermonStatusPending)
+	})
+
+	return claimed, err
+}
+
+// startHeartbeat extends the job's lease until the returned func is called
+func startHeartbeat(app core.App, job models.SermonAnalysisJob) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(heartbeatInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := extendLease(app, job); err != nil {
+					app.Logger().Error("SermonAnalysisJob: Unable to extend lease", "job", job.Id, "error", err.Error())
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func extendLease(app core.App, job models.SermonAnalysisJob) error {
+	now := types.NowDateTime()
+	res, err := app.DB().Update("analysis_jobs", dbx.Params{
+		"heartbeat_at":     now.String(),
+		"lease_expires_at": now.Add(leaseDuration).String(),
+	}, dbx.HashExp{"id": job.Id, "status": models.JobStatusRunning, "worker_id": WorkerId}).Execute()
+	if err != nil {
+		return err
+	}
+
+	// someone else reclaimed the job while we were stalled, don't steal it back
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errLeaseLost
+	}
+
+	return nil
+}
+
+// checkLease makes sure this worker still holds the job's lease
+func checkLease(app core.App, job models.SermonAnalysisJob) error {
+	record, err := app.FindRecordById("analysis_jobs", job.Id)
+	if err != nil {
+		return err
+	}
+
+	if record.GetString("status") != models.JobStatusRunning || record.GetString("worker_id") != WorkerId {
+		return errLeaseLost
+	}
+
+	return nil
+}
+
+// releaseLease hands a job this worker is still running back to the queue, e.g. when shutting down.
+// The interrupted attempt doesn't count against the job.
+func releaseLease(app core.App, job models.SermonAnalysisJob) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		res, err := txApp.DB().Update("analysis_jobs", dbx.Params{
+			"status":           models.JobStatusQueued,
+			"attempts":         dbx.NewExp("MAX(attempts - 1, 0)"),
+			"worker_id":        "",
+			"lease_expires_at": "",
+			"updated":          types.NowDateTime().String(),
+		}, dbx.HashExp{"id": job.Id, "status": models.JobStatusRunning, "worker_id": WorkerId}).Execute()
+		if err != nil {
+			return err
+		}
+		if rows, _ := res.RowsAffected(); rows == 0 {
+			return nil
+		}
+
+		return setStatus(txApp, job, models.SermonStatusCreated)
+	})
+}
+
+// ReclaimExpiredLeases puts running jobs whose worker stopped heartbeating back in the queue.
+// Running jobs without any lease predate leases entirely and are reclaimed as well.
+func ReclaimExpiredLeases(app core.App) {
+	reclaimLeases(app, dbx.Or(
+		dbx.HashExp{"lease_expires_at": ""},
+		dbx.NewExp("lease_expires_at < {:now}", dbx.Params{"now": types.NowDateTime().String()}),
+	))
+}
+
+// ReclaimStartupLeases runs on startup. Along with expired leases it reclaims any lease held by an
+// earlier process on this machine, since that process can't still be running.
+func ReclaimStartupLeases(app core.App) {
+	reclaimLeases(app, dbx.Or(
+		dbx.HashExp{"lease_expires_at": ""},
+		dbx.NewExp("lease_expires_at < {:now}", dbx.Params{"now": types.NowDateTime().String()}),
+		dbx.And(
+			dbx.Like("worker_id", workerHost()+"-").Match(false, true),
+			dbx.Not(dbx.HashExp{"worker_id": WorkerId}),
+		),
+	))
+}
+
+func reclaimLeases(app core.App, where dbx.Expression) {
+	stale := []models.SermonAnalysisJob{}
+	err := app.DB().
+		Select("*").
+		From("analysis_jobs").
+		Where(dbx.HashExp{"status": models.JobStatusRunning}).
+		AndWhere(where).
+		All(&stale)
+	if err != nil {
+		app.Logger().Error("SermonAnalysisJob: Error finding expired leases", "error", err.Error())
+		return
+	}
+
+	for _, job := range stale {
+		// the crashed attempt counts, otherwise a job that kills the worker would be retried forever
+		exhausted := job.Attempts >= maxAttempts(job)
+		jobStatus, sermonStatus := models.JobStatusQueued, models.SermonStatusCreated
+		if exhausted {
+			jobStatus, sermonStatus = models.JobStatusFailed, models.SermonStatusError
+		}
+
+		err := app.RunInTransaction(func(txApp core.App) error {
+			// only requeue if the lease is still the stale one we found, the worker may have just finished
+			res, err := txApp.DB().Update("analysis_jobs", dbx.Params{
+				"status":           jobStatus,
+				"worker_id":        "",
+				"lease_expires_at": "",
+				"updated":          types.NowDateTime().String(),
+			}, dbx.HashExp{
+				"id":               job.Id,
+				"status":           models.JobStatusRunning,
+				"worker_id":        job.WorkerId,
+				"lease_expires_at": job.LeaseExpiresAt.String(),
+			}).Execute()
+			if err != nil {
+				return err
+			}
+			if rows, _ := res.RowsAffected(); rows == 0 {
+				return nil
+			}
+
+			record, err := txApp.FindRecordById("analysis_jobs", job.Id)
+			if err != nil {
+				return err
+			}
+			msg := fmt.Sprintf("lease held by %q expired", job.WorkerId)
+			record.Set("last_error", msg)
+			err = appendAttempt(record, models.JobAttempt{
+				Attempt:    job.Attempts,
+				WorkerId:   job.WorkerId,
+				StartedAt:  job.ClaimedAt,
+				FinishedAt: types.NowDateTime(),
+				Error:      msg,
+				Retryable:  !exhausted,
+			})
+			if err != nil {
+				return err
+			}
+			if err := txApp.Save(record); err != nil {
+				return err
+			}
+
+			return setStatus(txApp, job, sermonStatus)
+		})
+		if err != nil {
+			app.Logger().Error("SermonAnalysisJob: Error reclaiming expired lease", "job", job.Id, "error", err.Error())
+			continue
+		}
+
+		app.Logger().Warn("SermonAnalysisJob: Reclaimed expired lease", "job", job.Id, "sermon", job.SermonId, "worker", job.WorkerId, "status", jobStatus)
+	}
+}
@@ -0,0 +1,190 @@
+package jobs
+
+import (
+	"api/internal/models"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// PoolConfig controls how a Pool runs analysis jobs
+type PoolConfig struct {
+	// Concurrency is the number of jobs analyzed at the same time
+	Concurrency int
+	// PollInterval is how often the pool looks for work without being woken,
+	// which picks up retries whose backoff has passed and reclaims expired leases
+	PollInterval time.Duration
+	// ShutdownTimeout is how long Stop waits for running jobs before handing them back to the queue
+	ShutdownTimeout time.Duration
+}
+
+// PoolConfigFromEnv reads ANALYSIS_CONCURRENCY (default 2) from the environment
+func PoolConfigFromEnv() PoolConfig {
+	cfg := PoolConfig{
+		Concurrency:     2,
+		PollInterval:    time.Minute,
+		ShutdownTimeout: 10 * time.Second,
+	}
+	if n, err := strconv.Atoi(os.Getenv("ANALYSIS_CONCURRENCY")); err == nil && n > 0 {
+		cfg.Concurrency = n
+	}
+	return cfg
+}
+
+// Pool is a fixed set of long lived workers analyzing sermons. It is woken as soon as an
+// analysis job is created, and polls on an interval to catch retries and expired leases.
+type Pool struct {
+	app core.App
+	cfg PoolConfig
+
+	wake    chan struct{}
+	queue   chan models.SermonAnalysisJob
+	done    chan struct{}
+	workers sync.WaitGroup
+
+	mu       sync.Mutex
+	running  map[string]models.SermonAnalysisJob
+	started  bool
+	stopOnce sync.Once
+}
+
+// NewPool creates a worker pool and wakes it whenever an analysis job is created
+func NewPool(app core.App, cfg PoolConfig) *Pool {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Minute
+	}
+
+	p := &Pool{
+		app:     app,
+		cfg:     cfg,
+		wake:    make(chan struct{}, 1),
+		queue:   make(chan models.SermonAnalysisJob),
+		done:    make(chan struct{}),
+		running: map[string]models.SermonAnalysisJob{},
+	}
+
+	app.OnRecordAfterCreateSuccess("analysis_jobs").BindFunc(func(e *core.RecordEvent) error {
+		p.Wake()
+		return e.Next()
+	})
+
+	return p
+}
+
+// Start launches the workers and the dispatcher
+func (p *Pool) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+
+	for range p.cfg.Concurrency {
+		p.workers.Add(1)
+		go p.work()
+	}
+	go p.dispatch()
+
+	p.app.Logger().Info("SermonAnalysisJob: Worker pool started", "concurrency", p.cfg.Concurrency, "worker", WorkerId)
+	p.Wake()
+}
+
+// Wake asks the pool to look for due jobs right away
+func (p *Pool) Wake() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+		// a wake up is already pending
+	}
+}
+
+// Stop stops handing out jobs and waits for running ones to finish. Jobs still running
+// after the shutdown timeout are released back to the queue for the next process.
+func (p *Pool) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+
+		finished := make(chan struct{})
+		go func() {
+			p.workers.Wait()
+			close(finished)
+		}()
+
+		select {
+		case <-finished:
+			p.app.Logger().Info("SermonAnalysisJob: Worker pool stopped")
+		case <-time.After(p.cfg.ShutdownTimeout):
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			for _, job := range p.running {
+				if err := releaseLease(p.app, job); err != nil {
+					p.app.Logger().Error("SermonAnalysisJob: Unable to release job on shutdown", "job", job.Id, "error", err.Error())
+					continue
+				}
+				p.app.Logger().Warn("SermonAnalysisJob: Released running job on shutdown", "job", job.Id)
+			}
+		}
+	})
+}
+
+func (p *Pool) dispatch() {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+
+		ReclaimExpiredLeases(p.app)
+
+		sermonJobs, err := dueJobs(p.app)
+		if err != nil {
+			p.app.Logger().Error("SermonAnalysisJob: Error getting sermon jobs", "error", err.Error())
+			continue
+		}
+
+		for _, job := range sermonJobs {
+			select {
+			case <-p.done:
+				return
+			case p.queue <- job:
+			}
+		}
+	}
+}
+
+func (p *Pool) work() {
+	defer p.workers.Done()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case job := <-p.queue:
+			p.track(job, true)
+			runJob(p.app, job)
+			p.track(job, false)
+		}
+	}
+}
+
+func (p *Pool) track(job models.SermonAnalysisJob, running bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if running {
+		p.running[job.Id] = job
+	} else {
+		delete(p.running, job.Id)
+	}
+}
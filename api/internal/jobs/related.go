@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"api/internal/embeddings"
+	"api/internal/models"
+	"api/internal/related"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// relateSermon works out the sermon's related sermons once it's complete, and adds it to the lists of
+// the sermons it's related to. Summaries are compared with the configured embedder's vectors, if any.
+func relateSermon(app core.App, job models.SermonAnalysisJob) {
+	model := ""
+	if embedder, err := embeddings.NewEmbedder(); err == nil {
+		model = embedder.Model()
+	}
+
+	if err := related.Compute(app, model, job.SermonId); err != nil {
+		app.Logger().Warn("SermonAnalysisJob: Error computing related sermons", "job", job.Id, "error", err.Error())
+	}
+}
@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"api/internal/ai"
+	"api/internal/models"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	defaultMaxAttempts  = 3
+	defaultRetryBackoff = time.Minute
+	maxRetryBackoff     = time.Hour
+	// last_error is a plain text field, keep it well under its size limit
+	maxErrorLength = 2000
+)
+
+// maxAttempts is the job's own limit when set, otherwise ANALYSIS_MAX_ATTEMPTS (default 3)
+func maxAttempts(job models.SermonAnalysisJob) int {
+	if job.MaxAttempts > 0 {
+		return job.MaxAttempts
+	}
+	if n, err := strconv.Atoi(os.Getenv("ANALYSIS_MAX_ATTEMPTS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxAttempts
+}
+
+// retryBackoff doubles ANALYSIS_RETRY_BACKOFF (default 1m) for every attempt already made, capped at an hour
+func retryBackoff(attempt int) time.Duration {
+	base := defaultRetryBackoff
+	if d, err := time.ParseDuration(os.Getenv("ANALYSIS_RETRY_BACKOFF")); err == nil && d > 0 {
+		base = d
+	}
+
+	backoff := base
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// finishAttempt records the outcome of a claimed attempt on the job and its sermon.
+// Transient errors put the job back in the queue with a backoff until it runs out of attempts.
+func finishAttempt(app core.App, job models.SermonAnalysisJob, startedAt types.DateTime, jobErr error) error {
+	retryable := ai.IsRetryable(jobErr)
+
+	err := app.RunInTransaction(func(txApp core.App) error {
+		// the job was handed to someone else (reclaimed or released on shutdown), it's theirs to record now
+		if err := checkLease(txApp, job); err != nil {
+			return err
+		}
+
+		record, err := txApp.FindRecordById("analysis_jobs", job.Id)
+		if err != nil {
+			return err
+		}
+
+		now := types.NowDateTime()
+		attempt := models.JobAttempt{
+			Attempt:    job.Attempts,
+			WorkerId:   WorkerId,
+			StartedAt:  startedAt,
+			FinishedAt: now,
+		}
+		if jobErr != nil {
+			attempt.Error = truncateError(jobErr.Error())
+			attempt.Retryable = retryable
+			record.Set("last_error", attempt.Error)
+		}
+		if err := appendAttempt(record, attempt); err != nil {
+			return err
+		}
+
+		record.Set("worker_id", "")
+		record.Set("lease_expires_at", "")
+		record.Set("next_attempt_at", "")
+
+		sermonStatus := models.SermonStatusComplete
+		switch {
+		case jobErr == nil:
+			record.Set("status", models.JobStatusComplete)
+		case retryable && job.Attempts < maxAttempts(job):
+			backoff := retryBackoff(job.Attempts)
+			record.Set("status", models.JobStatusQueued)
+			record.Set("next_attempt_at", now.Add(backoff))
+			sermonStatus = models.SermonStatusCreated
+			app.Logger().Warn("SermonAnalysisJob: Retrying job", "job", job.Id, "attempt", job.Attempts, "backoff", backoff.String())
+		default:
+			record.Set("status", models.JobStatusFailed)
+			sermonStatus = models.SermonStatusError
+		}
+
+		if err := txApp.Save(record); err != nil {
+			return err
+		}
+
+		return setStatus(txApp, job, sermonStatus)
+	})
+	if errors.Is(err, errLeaseLost) {
+		app.Logger().Warn("SermonAnalysisJob: Lost lease before recording attempt", "job", job.Id)
+	} else if err != nil {
+		app.Logger().Error("SermonAnalysisJob: ERROR: Unable to record job attempt", "job", job.Id, "error", err.Error())
+	}
+
+	return err
+}
+
+// appendAttempt adds an entry to the job record's attempt_history
+func appendAttempt(record *core.Record, attempt models.JobAttempt) error {
+	history := []models.JobAttempt{}
+	if raw := record.GetString("attempt_history"); raw != "" && raw != "null" {
+		if err := record.UnmarshalJSONField("attempt_history", &history); err != nil {
+			return err
+		}
+	}
+
+	record.Set("attempt_history", append(history, attempt))
+	return nil
+}
+
+func truncateError(msg string) string {
+	if len(msg) <= maxErrorLength {
+		return msg
+	}
+	return msg[:maxErrorLength] + "…"
+}
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"api/internal/ai"
+	"api/internal/models"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// loadTranscript returns a sermon's stored transcript segments in order
+func loadTranscript(app core.App, sermonId string) ([]models.TranscriptSegment, error) {
+	segments := []models.TranscriptSegment{}
+	err := app.DB().
+		Select("*").
+		From("sermon_transcripts").
+		Where(dbx.HashExp{"sermon_id": sermonId}).
+		OrderBy("start_ms", "id").
+		All(&segments)
+
+	return segments, err
+}
+
+// storeTranscript replaces the sermon's transcript in a single transaction
+func storeTranscript(app core.App, job models.SermonAnalysisJob, transcript ai.Transcript) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		if err := checkLease(txApp, job); err != nil {
+			return err
+		}
+
+		if err := deleteSermonRecords(txApp, "sermon_transcripts", job.SermonId); err != nil {
+			return err
+		}
+
+		collection, err := txApp.FindCollectionByNameOrId("sermon_transcripts")
+		if err != nil {
+			return err
+		}
+
+		for _, segment := range transcript.Segments {
+			record := core.NewRecord(collection)
+			record.Set("sermon_id", job.SermonId)
+			record.Set("start_ms", segment.StartMs)
+			record.Set("end_ms", segment.EndMs)
+			record.Set("text", segment.Text)
+			if err := txApp.Save(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
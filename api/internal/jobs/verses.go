@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"api/internal/models"
+	"api/internal/scripture"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// noteVerses are the references from one field of a note, checked against the Bible
+type noteVerses struct {
+	field    string
+	kind     string
+	refs     []scripture.Ref
+	problems []*scripture.ParseError
+}
+
+// checkVerses parses what the model wrote in a note's verse field. References that don't exist or
+// can't be understood are dropped from refs and returned as problems instead.
+func checkVerses(field string, kind string, text string) noteVerses {
+	refs, err := scripture.Parse(text)
+	verses := noteVerses{field: field, kind: kind, refs: refs}
+
+	var problems scripture.ParseErrors
+	if errors.As(err, &problems) {
+		verses.problems = problems
+	}
+	return verses
+}
+
+// saveVerses stores the note's references and a warning for each problem with them
+func saveVerses(app core.App, job models.SermonAnalysisJob, detailId string, verses noteVerses) error {
+	refsCollection, err := app.FindCollectionByNameOrId("sermon_verse_refs")
+	if err != nil {
+		return err
+	}
+	for _, ref := range verses.refs {
+		record := core.NewRecord(refsCollection)
+		record.Set("sermon_id", job.SermonId)
+		record.Set("detail_id", detailId)
+		record.Set("kind", verses.kind)
+		record.Set("book", ref.Book)
+		record.Set("chapter", ref.Chapter)
+		record.Set("verse", ref.Verse)
+		record.Set("end_chapter", ref.EndChapter)
+		record.Set("end_verse", ref.EndVerse)
+		record.Set("reference", ref.String())
+		if err := app.Save(record); err != nil {
+			return err
+		}
+	}
+
+	if len(verses.problems) == 0 {
+		return nil
+	}
+
+	warningsCollection, err := app.FindCollectionByNameOrId("sermon_warnings")
+	if err != nil {
+		return err
+	}
+	for _, problem := range verses.problems {
+		app.Logger().Warn("SermonAnalysisJob: Dropped invalid verse reference", "job", job.Id, "reference", problem.Text, "error", problem.Err.Error())
+
+		record := core.NewRecord(warningsCollection)
+		record.Set("sermon_id", job.SermonId)
+		record.Set("detail_id", detailId)
+		record.Set("field", verses.field)
+		record.Set("reference", problem.Text)
+		record.Set("message", problem.Err.Error())
+		if err := app.Save(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
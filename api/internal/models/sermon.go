@@ -1,30 +1,87 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
 
 const (
 	SermonStatusCreated  = "created"
 	SermonStatusPending  = "pending"
 	SermonStatusComplete = "complete"
 	SermonStatusError    = "error"
+	SermonStatusDeleted  = "deleted"
+)
+
+const (
+	JobStatusQueued   = "queued"
+	JobStatusRunning  = "running"
+	JobStatusComplete = "complete"
+	JobStatusFailed   = "failed"
 )
 
+// Job types decide which parts of a sermon's analysis a job replaces
+const (
+	JobTypeFull      = "full"
+	JobTypeSummary   = "summary"
+	JobTypeNotes     = "notes"
+	JobTypeQuestions = "questions"
+	// A full analysis that transcribes the recording again instead of using the stored transcript
+	JobTypeTranscript = "transcript"
+)
+
+var JobTypes = []string{JobTypeFull, JobTypeSummary, JobTypeNotes, JobTypeQuestions, JobTypeTranscript}
+
 type Sermon struct {
-	Id        string    `json:"id" db:"id"`
-	Title     string    `json:"title" db:"title"`
-	Status    string    `json:"status" db:"status"`
-	Date      time.Time `json:"date_given" db:"date_given"`
-	Summary   string    `json:"summary" db:"summary"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	Id            string                  `json:"id" db:"id"`
+	Title         string                  `json:"title" db:"title"`
+	Status        string                  `json:"status" db:"status"`
+	Date          time.Time               `json:"date_given" db:"date_given"`
+	Summary       string                  `json:"summary" db:"summary"`
+	Topics        types.JSONArray[string] `json:"topics" db:"topics"`   // Lowercase themes, used to find related sermons
+	Speaker       string                  `json:"speaker" db:"speaker"` // Name of the speaker, kept in step with SpeakerId
+	SpeakerId     string                  `json:"speaker_id" db:"speaker_id"`
+	AudioFile     string                  `json:"audio_file" db:"audio_file"`
+	AudioDuration float64                 `json:"audio_duration" db:"audio_duration"` // Seconds, of the normalized audio that was analyzed
+	AudioSize     int64                   `json:"audio_size" db:"audio_size"`         // Bytes, of the normalized audio that was analyzed
+	CreatedAt     time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at" db:"updated_at"`
 }
 
 type SermonAnalysisJob struct {
-	Id        string    `json:"id" db:"id"`
-	SermonId  string    `json:"sermon_id" db:"sermon_id"`
-	AudioURL  string    `json:"audio_url" db:"audio_url"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	Id             string         `json:"id" db:"id"`
+	SermonId       string         `json:"sermon_id" db:"sermon_id"`
+	AudioURL       string         `json:"audio_url" db:"audio_url"`
+	StartOffset    string         `json:"start_offset" db:"start_offset"` // Where the sermon starts in the recording, e.g. 41:20
+	EndOffset      string         `json:"end_offset" db:"end_offset"`     // Where it ends, e.g. 1:18:05
+	Status         string         `json:"status" db:"status"`
+	JobType        string         `json:"job_type" db:"job_type"`
+	WorkerId       string         `json:"worker_id" db:"worker_id"`               // Worker currently holding the lease
+	ClaimedAt      types.DateTime `json:"claimed_at" db:"claimed_at"`             // When the current lease was taken
+	LeaseExpiresAt types.DateTime `json:"lease_expires_at" db:"lease_expires_at"` // Lease is reclaimable after this
+	HeartbeatAt    types.DateTime `json:"heartbeat_at" db:"heartbeat_at"`         // Last time the worker extended the lease
+	Attempts       int            `json:"attempts" db:"attempts"`
+	MaxAttempts    int            `json:"max_attempts" db:"max_attempts"` // 0 uses the configured default
+	LastError      string         `json:"last_error" db:"last_error"`
+	NextAttemptAt  types.DateTime `json:"next_attempt_at" db:"next_attempt_at"` // Job isn't picked up again before this
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Replaces reports whether the job writes the given part (one of the partial job types) of the analysis
+func (j SermonAnalysisJob) Replaces(part string) bool {
+	return j.JobType == "" || j.JobType == JobTypeFull || j.JobType == JobTypeTranscript || j.JobType == part
+}
+
+// JobAttempt is a single entry in an analysis job's attempt_history
+type JobAttempt struct {
+	Attempt    int            `json:"attempt"`
+	WorkerId   string         `json:"worker_id"`
+	StartedAt  types.DateTime `json:"started_at"`
+	FinishedAt types.DateTime `json:"finished_at"`
+	Error      string         `json:"error,omitempty"`
+	Retryable  bool           `json:"retryable,omitempty"`
 }
 
 type SermonDetail struct {
@@ -48,3 +105,82 @@ type SermonQuestion struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// TranscriptSegment is a stretch of a sermon's transcript, timestamped from the start of the analyzed audio
+type TranscriptSegment struct {
+	Id        string    `json:"id" db:"id"`
+	SermonId  string    `json:"sermon_id" db:"sermon_id"`
+	StartMs   int64     `json:"start_ms" db:"start_ms"`
+	EndMs     int64     `json:"end_ms" db:"end_ms"`
+	Text      string    `json:"text" db:"text"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Verse reference kinds, whether it was a note's key verse or one of its relevant verses
+const (
+	VerseRefKey      = "key"
+	VerseRefRelevant = "relevant"
+)
+
+// SermonVerseRef is a scripture reference from a sermon's notes, see scripture.Ref
+type SermonVerseRef struct {
+	Id         string    `json:"id" db:"id"`
+	SermonId   string    `json:"sermon_id" db:"sermon_id"`
+	DetailId   string    `json:"detail_id" db:"detail_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	Book       string    `json:"book" db:"book"` // USFM book code
+	Chapter    int       `json:"chapter" db:"chapter"`
+	Verse      int       `json:"verse" db:"verse"` // 0 when the reference is whole chapters
+	EndChapter int       `json:"end_chapter" db:"end_chapter"`
+	EndVerse   int       `json:"end_verse" db:"end_verse"`
+	Reference  string    `json:"reference" db:"reference"` // Canonical text, e.g. Matt 5:4-9
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SermonWarning is something wrong with a sermon's analysis for an admin to review,
+// like a verse reference that doesn't exist
+type SermonWarning struct {
+	Id        string    `json:"id" db:"id"`
+	SermonId  string    `json:"sermon_id" db:"sermon_id"`
+	DetailId  string    `json:"detail_id" db:"detail_id"`
+	Field     string    `json:"field" db:"field"`         // Field of the note, e.g. key_verse
+	Reference string    `json:"reference" db:"reference"` // What the model wrote
+	Message   string    `json:"message" db:"message"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// What an embedding was computed from
+const (
+	EmbeddingSourceSummary = "summary"
+	EmbeddingSourceNote    = "note"
+)
+
+// SermonEmbedding is the vector of a sermon's summary or one of its notes, see the embeddings package
+type SermonEmbedding struct {
+	Id          string        `json:"id" db:"id"`
+	SermonId    string        `json:"sermon_id" db:"sermon_id"`
+	Source      string        `json:"source" db:"source"`
+	RecordId    string        `json:"record_id" db:"record_id"` // The sermon for summaries, the sermon_details record for notes
+	Model       string        `json:"model" db:"model"`         // Embedder that computed the vector
+	ContentHash string        `json:"content_hash" db:"content_hash"`
+	Vector      types.JSONRaw `json:"vector" db:"vector"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// SermonRelated is another sermon recommended after reading a sermon, see the related package
+type SermonRelated struct {
+	Id           string                  `json:"id" db:"id"`
+	SermonId     string                  `json:"sermon_id" db:"sermon_id"`
+	RelatedId    string                  `json:"related_id" db:"related_id"`
+	Score        float64                 `json:"score" db:"score"`
+	SharedRefs   types.JSONArray[string] `json:"shared_refs" db:"shared_refs"` // The sermon's references that overlap the related sermon's
+	SharedTopics types.JSONArray[string] `json:"shared_topics" db:"shared_topics"`
+	SameSpeaker  bool                    `json:"same_speaker" db:"same_speaker"`
+	Similarity   float64                 `json:"similarity" db:"similarity"` // Of the summaries' embeddings, 0 when either has none
+	CreatedAt    time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Speaker is someone who gives sermons
+type Speaker struct {
+	Id        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Bio       string    `json:"bio" db:"bio"`
+	Photo     string    `json:"photo" db:"photo"`
+	Role      string    `json:"role" db:"role"` // e.g. Pastor, or Guest
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,314 @@
+package related
+
+import (
+	"api/internal/embeddings"
+	"api/internal/models"
+	"api/internal/scripture"
+	"sort"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection holds each sermon's related sermons
+const Collection = "sermon_related"
+
+// Limit is how many related sermons are kept for each sermon
+const Limit = 10
+
+// How much each thing two sermons have in common adds to their score
+const (
+	// per pair of overlapping references, by whether each was a key verse or supporting
+	keyKeyWeight               = 3.0
+	keySupportingWeight        = 1.5
+	supportingSupportingWeight = 0.5
+	maxRefScore                = 9.0
+
+	topicWeight   = 1.5
+	maxTopicScore = 4.5
+
+	speakerWeight = 1.0
+
+	// summary similarity below the floor counts for nothing, embeddings of unrelated text are rarely far apart
+	similarityWeight = 4.0
+	similarityFloor  = 0.2
+
+	// sermons scoring less aren't related, only sharing a speaker isn't enough
+	minScore = 1.5
+)
+
+// Relation is how related one sermon is to another and why
+type Relation struct {
+	SermonId     string
+	RelatedId    string
+	Score        float64
+	SharedRefs   []string // The sermon's references that overlap the related sermon's, key verses first
+	SharedTopics []string
+	SameSpeaker  bool
+	Similarity   float64
+}
+
+// features are what's compared between sermons
+type features struct {
+	id      string
+	speaker string
+	topics  []string
+	refs    []models.SermonVerseRef
+	vector  []float32
+}
+
+type sermonRow struct {
+	Id        string                  `db:"id"`
+	SpeakerId string                  `db:"speaker_id"`
+	Topics    types.JSONArray[string] `db:"topics"`
+}
+
+// load gathers the features of every complete sermon, with the summary vectors of the model
+func load(app core.App, model string) (map[string]*features, error) {
+	rows := []sermonRow{}
+	err := app.DB().
+		Select("id", "speaker_id", "topics").
+		From("sermons").
+		Where(dbx.HashExp{"status": models.SermonStatusComplete}).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	sermons := make(map[string]*features, len(rows))
+	for _, row := range rows {
+		sermons[row.Id] = &features{
+			id:      row.Id,
+			speaker: row.SpeakerId,
+			topics:  row.Topics,
+		}
+	}
+
+	refs := []models.SermonVerseRef{}
+	err = app.DB().
+		Select("r.*").
+		From("sermon_verse_refs r").
+		InnerJoin("sermons s", dbx.NewExp("s.id = r.sermon_id")).
+		Where(dbx.HashExp{"s.status": models.SermonStatusComplete}).
+		All(&refs)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if sermon := sermons[ref.SermonId]; sermon != nil {
+			sermon.refs = append(sermon.refs, ref)
+		}
+	}
+
+	if model != "" {
+		vectors, err := embeddings.Summaries(app, model)
+		if err != nil {
+			return nil, err
+		}
+		for id, vector := range vectors {
+			if sermon := sermons[id]; sermon != nil {
+				sermon.vector = vector
+			}
+		}
+	}
+
+	return sermons, nil
+}
+
+// relate scores how related the other sermon is to the sermon. The score is the same both ways round,
+// only the shared references are from the sermon's side.
+func relate(sermon *features, other *features) Relation {
+	relation := Relation{SermonId: sermon.id, RelatedId: other.id, SharedRefs: []string{}, SharedTopics: []string{}}
+
+	refScore := 0.0
+	shared := map[string]bool{}
+	for _, ref := range sermon.refs {
+		for _, otherRef := range other.refs {
+			if !toRef(ref).Overlaps(toRef(otherRef)) {
+				continue
+			}
+			switch {
+			case ref.Kind == models.VerseRefKey && otherRef.Kind == models.VerseRefKey:
+				refScore += keyKeyWeight
+			case ref.Kind == models.VerseRefKey || otherRef.Kind == models.VerseRefKey:
+				refScore += keySupportingWeight
+			default:
+				refScore += supportingSupportingWeight
+			}
+			if !shared[ref.Reference] {
+				shared[ref.Reference] = true
+				relation.SharedRefs = append(relation.SharedRefs, ref.Reference)
+			}
+		}
+	}
+	relation.Score += min(refScore, maxRefScore)
+
+	otherTopics := map[string]bool{}
+	for _, topic := range other.topics {
+		otherTopics[topic] = true
+	}
+	for _, topic := range sermon.topics {
+		if otherTopics[topic] {
+			relation.SharedTopics = append(relation.SharedTopics, topic)
+		}
+	}
+	relation.Score += min(topicWeight*float64(len(relation.SharedTopics)), maxTopicScore)
+
+	if sermon.speaker != "" && sermon.speaker == other.speaker {
+		relation.SameSpeaker = true
+		relation.Score += speakerWeight
+	}
+
+	if sermon.vector != nil && other.vector != nil {
+		relation.Similarity = max(embeddings.Similarity(sermon.vector, other.vector), 0)
+		relation.Score += similarityWeight * max(relation.Similarity-similarityFloor, 0) / (1 - similarityFloor)
+	}
+
+	return relation
+}
+
+func toRef(ref models.SermonVerseRef) scripture.Ref {
+	return scripture.Ref{Book: ref.Book, Chapter: ref.Chapter, Verse: ref.Verse, EndChapter: ref.EndChapter, EndVerse: ref.EndVerse}
+}
+
+// best keeps the highest scoring relations that are related enough, at most Limit of them
+func best(relations []Relation) []Relation {
+	kept := []Relation{}
+	for _, relation := range relations {
+		if relation.Score >= minScore {
+			kept = append(kept, relation)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].Score != kept[j].Score {
+			return kept[i].Score > kept[j].Score
+		}
+		return kept[i].RelatedId < kept[j].RelatedId
+	})
+	if len(kept) > Limit {
+		kept = kept[:Limit]
+	}
+	return kept
+}
+
+// Compute works out the sermon's related sermons, and puts the sermon in the lists of the others it's
+// related to enough. A sermon that isn't complete has its list cleared. model is the embedder whose
+// summary vectors are compared, empty to leave summaries out.
+func Compute(app core.App, model string, sermonId string) error {
+	sermons, err := load(app, model)
+	if err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		sermon := sermons[sermonId]
+		if sermon == nil {
+			return replace(txApp, sermonId, nil)
+		}
+
+		relations := []Relation{}
+		for _, other := range sermons {
+			if other.id == sermonId {
+				continue
+			}
+			relations = append(relations, relate(sermon, other))
+
+			// the other sermon's list changes only where this sermon falls in it
+			existing := []models.SermonRelated{}
+			err := txApp.DB().
+				Select("*").
+				From(Collection).
+				Where(dbx.HashExp{"sermon_id": other.id}).
+				All(&existing)
+			if err != nil {
+				return err
+			}
+			theirs := []Relation{relate(other, sermon)}
+			listed := false
+			for _, row := range existing {
+				if row.RelatedId == sermonId {
+					listed = true
+				} else {
+					theirs = append(theirs, fromRow(row))
+				}
+			}
+			if !listed && theirs[0].Score < minScore {
+				continue
+			}
+			if err := replace(txApp, other.id, best(theirs)); err != nil {
+				return err
+			}
+		}
+
+		return replace(txApp, sermonId, best(relations))
+	})
+}
+
+// ComputeAll works out the related sermons of every complete sermon
+func ComputeAll(app core.App, model string) error {
+	sermons, err := load(app, model)
+	if err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, sermon := range sermons {
+			relations := []Relation{}
+			for _, other := range sermons {
+				if other.id != sermon.id {
+					relations = append(relations, relate(sermon, other))
+				}
+			}
+			if err := replace(txApp, sermon.id, best(relations)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func fromRow(row models.SermonRelated) Relation {
+	return Relation{
+		SermonId:     row.SermonId,
+		RelatedId:    row.RelatedId,
+		Score:        row.Score,
+		SharedRefs:   row.SharedRefs,
+		SharedTopics: row.SharedTopics,
+		SameSpeaker:  row.SameSpeaker,
+		Similarity:   row.Similarity,
+	}
+}
+
+// replace swaps the sermon's stored related sermons for the relations
+func replace(app core.App, sermonId string, relations []Relation) error {
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		return err
+	}
+
+	existing, err := app.FindAllRecords(collection, dbx.HashExp{"sermon_id": sermonId})
+	if err != nil {
+		return err
+	}
+	for _, record := range existing {
+		if err := app.Delete(record); err != nil {
+			return err
+		}
+	}
+
+	for _, relation := range relations {
+		record := core.NewRecord(collection)
+		record.Set("sermon_id", relation.SermonId)
+		record.Set("related_id", relation.RelatedId)
+		record.Set("score", relation.Score)
+		record.Set("shared_refs", relation.SharedRefs)
+		record.Set("shared_topics", relation.SharedTopics)
+		record.Set("same_speaker", relation.SameSpeaker)
+		record.Set("similarity", relation.Similarity)
+		if err := app.Save(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
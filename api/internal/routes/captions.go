@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"api/internal/captions"
+	"api/internal/models"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// captionFormats maps each caption file extension to its content type and writer
+var captionFormats = map[string]struct {
+	contentType string
+	write       func(w io.Writer, cues []captions.Cue) error
+}{
+	"vtt": {"text/vtt; charset=utf-8", captions.WriteVTT},
+	"srt": {"application/x-subrip; charset=utf-8", captions.WriteSRT},
+}
+
+// sermonCaptions renders a sermon's stored transcript as a caption file. The sermon is only
+// visible to whoever can view it through the sermons collection.
+func sermonCaptions(format string) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		sermon, err := e.App.FindRecordById("sermons", e.Request.PathValue("id"))
+		if err != nil {
+			return e.NotFoundError("Sermon not found.", err)
+		}
+
+		info, err := e.RequestInfo()
+		if err != nil {
+			return e.BadRequestError("", err)
+		}
+		canView, err := e.App.CanAccessRecord(sermon, info, sermon.Collection().ViewRule)
+		if !canView {
+			return e.NotFoundError("Sermon not found.", err)
+		}
+
+		segments := []models.TranscriptSegment{}
+		err = e.App.DB().
+			Select("*").
+			From("sermon_transcripts").
+			Where(dbx.HashExp{"sermon_id": sermon.Id}).
+			OrderBy("start_ms", "id").
+			All(&segments)
+		if err != nil {
+			return e.InternalServerError("Failed to load the sermon's transcript.", err)
+		}
+		if len(segments) == 0 {
+			return e.NotFoundError("Sermon has no transcript.", nil)
+		}
+
+		var buf bytes.Buffer
+		if err := captionFormats[format].write(&buf, captions.Cues(segments, captions.DefaultOptions())); err != nil {
+			return e.InternalServerError("Failed to render the captions.", err)
+		}
+
+		e.Response.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", sermon.Id+"."+format))
+		return e.Blob(http.StatusOK, captionFormats[format].contentType, buf.Bytes())
+	}
+}
@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"api/internal/models"
+	"api/internal/scripture"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type coverageResponse struct {
+	From          string          `json:"from"` // Date window, empty when unbounded
+	To            string          `json:"to"`
+	Sermons       int             `json:"sermons"` // Complete sermons in the window
+	Books         []*bookCoverage `json:"books"`   // Books with any references, in canonical order
+	NeverPreached []*bookCoverage `json:"never_preached"`
+}
+
+// coverage counts the sermons that referenced a book or chapter. A sermon counts as key if any
+// of its notes had it as a key verse, otherwise as supporting.
+type coverage struct {
+	Sermons           int      `json:"sermons"`
+	KeySermons        int      `json:"key_sermons"`
+	SupportingSermons int      `json:"supporting_sermons"`
+	Dates             []string `json:"dates"` // Dates the sermons were given, oldest first
+	kinds             map[string]string
+	dates             map[string]string
+}
+
+type bookCoverage struct {
+	Book         string             `json:"book"`
+	Name         string             `json:"name"`
+	OldTestament bool               `json:"old_testament"`
+	Chapters     int                `json:"chapters"`         // Chapters in the book
+	Covered      int                `json:"chapters_covered"` // Chapters with any references
+	Coverage     []*chapterCoverage `json:"coverage"`
+	coverage
+}
+
+type chapterCoverage struct {
+	Chapter int `json:"chapter"`
+	coverage
+}
+
+// coverageRow is a stored verse reference along with its sermon's date
+type coverageRow struct {
+	SermonId   string         `db:"sermon_id"`
+	Kind       string         `db:"kind"`
+	Book       string         `db:"book"`
+	Chapter    int            `db:"chapter"`
+	EndChapter int            `db:"end_chapter"`
+	DateGiven  types.DateTime `db:"date_given"`
+}
+
+// coverageReport aggregates the verse references of complete sermons given between the optional from
+// and to dates (YYYY-MM-DD, inclusive) into coverage per book and chapter, and lists the books no
+// sermon in the window took a key verse from.
+func coverageReport(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+	where := dbx.And(dbx.HashExp{"s.status": models.SermonStatusComplete})
+
+	response := &coverageResponse{From: query.Get("from"), To: query.Get("to")}
+	if response.From != "" {
+		from, err := time.Parse(time.DateOnly, response.From)
+		if err != nil {
+			return e.BadRequestError("Invalid from date, expected YYYY-MM-DD.", err)
+		}
+		where = dbx.And(where, dbx.NewExp("s.date_given >= {:from}", dbx.Params{"from": from.Format(types.DefaultDateLayout)}))
+	}
+	if response.To != "" {
+		to, err := time.Parse(time.DateOnly, response.To)
+		if err != nil {
+			return e.BadRequestError("Invalid to date, expected YYYY-MM-DD.", err)
+		}
+		where = dbx.And(where, dbx.NewExp("s.date_given != '' AND s.date_given < {:to}", dbx.Params{"to": to.AddDate(0, 0, 1).Format(types.DefaultDateLayout)}))
+	}
+
+	sermons := []string{}
+	err := e.App.DB().
+		Select("s.id").
+		From("sermons s").
+		Where(where).
+		Column(&sermons)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+	response.Sermons = len(sermons)
+
+	rows := []coverageRow{}
+	err = e.App.DB().
+		Select("r.sermon_id", "r.kind", "r.book", "r.chapter", "r.end_chapter", "s.date_given").
+		From("sermon_verse_refs r").
+		InnerJoin("sermons s", dbx.NewExp("s.id = r.sermon_id")).
+		Where(where).
+		All(&rows)
+	if err != nil {
+		return e.InternalServerError("Failed to load verse references.", err)
+	}
+
+	books := map[string]*bookCoverage{}
+	chapters := map[string]map[int]*chapterCoverage{}
+	for _, row := range rows {
+		book, ok := scripture.BookByID(row.Book)
+		if !ok {
+			continue
+		}
+		date := ""
+		if !row.DateGiven.IsZero() {
+			date = row.DateGiven.Time().Format(time.DateOnly)
+		}
+
+		if books[book.ID] == nil {
+			books[book.ID] = &bookCoverage{Book: book.ID, Name: book.Name, OldTestament: book.IsOldTestament(), Chapters: book.Chapters()}
+			chapters[book.ID] = map[int]*chapterCoverage{}
+		}
+		books[book.ID].add(row.SermonId, row.Kind, date)
+
+		for chapter := row.Chapter; chapter <= row.EndChapter; chapter++ {
+			if chapters[book.ID][chapter] == nil {
+				chapters[book.ID][chapter] = &chapterCoverage{Chapter: chapter}
+			}
+			chapters[book.ID][chapter].add(row.SermonId, row.Kind, date)
+		}
+	}
+
+	response.Books = []*bookCoverage{}
+	response.NeverPreached = []*bookCoverage{}
+	for _, book := range scripture.Books() {
+		covered := books[book.ID]
+		if covered == nil {
+			covered = &bookCoverage{Book: book.ID, Name: book.Name, OldTestament: book.IsOldTestament(), Chapters: book.Chapters()}
+		}
+		covered.finish()
+
+		covered.Coverage = []*chapterCoverage{}
+		for _, chapter := range chapters[book.ID] {
+			chapter.finish()
+			covered.Coverage = append(covered.Coverage, chapter)
+		}
+		sort.Slice(covered.Coverage, func(i, j int) bool {
+			return covered.Coverage[i].Chapter < covered.Coverage[j].Chapter
+		})
+		covered.Covered = len(covered.Coverage)
+
+		if covered.Sermons > 0 {
+			response.Books = append(response.Books, covered)
+		}
+		if covered.KeySermons == 0 {
+			response.NeverPreached = append(response.NeverPreached, covered)
+		}
+	}
+
+	return e.JSON(http.StatusOK, response)
+}
+
+// add counts a reference from a sermon, a sermon is only counted once
+func (c *coverage) add(sermonId string, kind string, date string) {
+	if c.kinds == nil {
+		c.kinds = map[string]string{}
+		c.dates = map[string]string{}
+	}
+	if c.kinds[sermonId] != models.VerseRefKey {
+		c.kinds[sermonId] = kind
+	}
+	c.dates[sermonId] = date
+}
+
+// finish fills in the counts once every reference has been added
+func (c *coverage) finish() {
+	c.Sermons, c.KeySermons, c.SupportingSermons = len(c.kinds), 0, 0
+	for _, kind := range c.kinds {
+		if kind == models.VerseRefKey {
+			c.KeySermons++
+		} else {
+			c.SupportingSermons++
+		}
+	}
+
+	seen := map[string]bool{}
+	c.Dates = []string{}
+	for _, date := range c.dates {
+		if date != "" && !seen[date] {
+			seen[date] = true
+			c.Dates = append(c.Dates, date)
+		}
+	}
+	sort.Strings(c.Dates)
+}
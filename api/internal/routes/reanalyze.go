@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"api/internal/models"
+	"net/http"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type reanalyzeRequest struct {
+	JobType string `json:"job_type" form:"job_type"`
+}
+
+// reanalyzeSermon queues a new analysis job for a sermon. Partial job types only replace their part
+// of the analysis, so they need a sermon that has been analyzed successfully before.
+func reanalyzeSermon(e *core.RequestEvent) error {
+	body := reanalyzeRequest{}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid request body.", err)
+	}
+	if body.JobType == "" {
+		body.JobType = models.JobTypeFull
+	}
+	if !slices.Contains(models.JobTypes, body.JobType) {
+		return e.BadRequestError("Unknown job type.", map[string]any{"job_type": models.JobTypes})
+	}
+
+	sermon, err := e.App.FindRecordById("sermons", e.Request.PathValue("id"))
+	if err != nil || sermon.GetString("status") == models.SermonStatusDeleted {
+		return e.NotFoundError("Sermon not found.", err)
+	}
+
+	switch sermon.GetString("status") {
+	case models.SermonStatusCreated, models.SermonStatusPending:
+		return e.Error(http.StatusConflict, "Sermon is still being analyzed.", nil)
+	case models.SermonStatusError:
+		if body.JobType != models.JobTypeFull && body.JobType != models.JobTypeTranscript {
+			return e.BadRequestError("Sermon has no analysis yet, only a full analysis can be run.", nil)
+		}
+	}
+
+	active, err := e.App.CountRecords("analysis_jobs",
+		dbx.HashExp{"sermon_id": sermon.Id},
+		dbx.In("status", models.JobStatusQueued, models.JobStatusRunning),
+	)
+	if err != nil {
+		return e.InternalServerError("Failed to check for running jobs.", err)
+	}
+	if active > 0 {
+		return e.Error(http.StatusConflict, "Sermon already has an analysis job queued.", nil)
+	}
+
+	previous, err := e.App.FindRecordsByFilter("analysis_jobs", "sermon_id = {:id}", "-created", 1, 0, dbx.Params{"id": sermon.Id})
+	if err != nil {
+		return e.InternalServerError("Failed to find the sermon's previous analysis.", err)
+	}
+	audioURL, startOffset, endOffset := "", "", ""
+	if len(previous) > 0 {
+		audioURL = previous[0].GetString("audio_url")
+		startOffset = previous[0].GetString("start_offset")
+		endOffset = previous[0].GetString("end_offset")
+	}
+	if audioURL == "" && sermon.GetString("audio_file") == "" {
+		return e.BadRequestError("Sermon has no audio to analyze.", nil)
+	}
+
+	collection, err := e.App.FindCollectionByNameOrId("analysis_jobs")
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	job := core.NewRecord(collection)
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		job.Set("sermon_id", sermon.Id)
+		job.Set("audio_url", audioURL)
+		job.Set("start_offset", startOffset)
+		job.Set("end_offset", endOffset)
+		job.Set("job_type", body.JobType)
+		if err := txApp.Save(job); err != nil {
+			return err
+		}
+
+		// a sermon that failed goes back through the regular created -> pending -> complete flow
+		if sermon.GetString("status") == models.SermonStatusError {
+			sermon.Set("status", models.SermonStatusCreated)
+			return txApp.Save(sermon)
+		}
+		return nil
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to queue the analysis job.", err)
+	}
+
+	return e.JSON(http.StatusCreated, job)
+}
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"api/internal/models"
+	"api/internal/related"
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type relatedResponse struct {
+	Items []*relatedItem `json:"items"`
+}
+
+// relatedItem is a recommended sermon and what it has in common with the one being read
+type relatedItem struct {
+	Sermon       *core.Record `json:"sermon"`
+	Score        float64      `json:"score"`
+	SharedRefs   []string     `json:"shared_refs"` // The sermon's references that overlap the related sermon's
+	SharedTopics []string     `json:"shared_topics"`
+	SameSpeaker  bool         `json:"same_speaker"`
+	Similarity   float64      `json:"similarity"`
+}
+
+// sermonRelated lists the other complete sermons most related to the sermon, worked out when it was
+// analyzed, best first
+func sermonRelated(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+	sermons, err := visibleSermons(e, []string{id})
+	if err != nil {
+		return e.InternalServerError("Failed to load sermon.", err)
+	}
+	if sermons[id] == nil {
+		return e.NotFoundError("Sermon not found.", nil)
+	}
+
+	rows := []models.SermonRelated{}
+	err = e.App.DB().
+		Select("*").
+		From(related.Collection).
+		Where(dbx.HashExp{"sermon_id": id}).
+		OrderBy("score DESC", "related_id").
+		All(&rows)
+	if err != nil {
+		return e.InternalServerError("Failed to load related sermons.", err)
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.RelatedId
+	}
+	relatedSermons, err := visibleSermons(e, ids)
+	if err != nil {
+		return e.InternalServerError("Failed to load related sermons.", err)
+	}
+
+	response := relatedResponse{Items: []*relatedItem{}}
+	for _, row := range rows {
+		sermon := relatedSermons[row.RelatedId]
+		if sermon == nil || sermon.GetString("status") != models.SermonStatusComplete {
+			continue
+		}
+		response.Items = append(response.Items, &relatedItem{
+			Sermon:       sermon,
+			Score:        row.Score,
+			SharedRefs:   row.SharedRefs,
+			SharedTopics: row.SharedTopics,
+			SameSpeaker:  row.SameSpeaker,
+			Similarity:   row.Similarity,
+		})
+	}
+
+	return e.JSON(http.StatusOK, response)
+}
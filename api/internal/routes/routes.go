@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"api/internal/models"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// ConfigureRoutes registers the custom api routes
+func ConfigureRoutes(app core.App) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.POST("/api/sermons/{id}/reanalyze", reanalyzeSermon).
+			Bind(apis.RequireAuth(), requireAdmin())
+		for format := range captionFormats {
+			se.Router.GET("/api/sermons/{id}/captions."+format, sermonCaptions(format))
+		}
+		se.Router.GET("/api/sermons/{id}/related", sermonRelated)
+		se.Router.GET("/api/speakers", listSpeakers)
+		se.Router.GET("/api/speakers/{id}/sermons", listSpeakerSermons)
+		se.Router.GET("/api/speakers/{id}/stats", speakerStats)
+		se.Router.GET("/api/scripture/lookup", scriptureLookup)
+		se.Router.GET("/api/scripture/text", scriptureText)
+		se.Router.GET("/api/search", searchSermons)
+		se.Router.GET("/api/search/semantic", semanticSearch)
+		se.Router.GET("/api/search/hybrid", hybridSearch)
+		se.Router.POST("/api/embeddings/rebuild", rebuildEmbeddings).
+			Bind(apis.RequireAuth(), requireAdmin())
+		se.Router.GET("/api/reports/coverage", coverageReport).
+			Bind(apis.RequireAuth(), requireAdmin())
+
+		return se.Next()
+	})
+}
+
+// requireAdmin only lets through users with the admin role (and superusers)
+func requireAdmin() *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Func: func(e *core.RequestEvent) error {
+			if e.HasSuperuserAuth() {
+				return e.Next()
+			}
+			if e.Auth == nil || e.Auth.GetString("role") != models.UserRoleAdmin {
+				return e.ForbiddenError("Only admins can perform this action.", nil)
+			}
+			return e.Next()
+		},
+	}
+}
@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"api/internal/bible"
+	"api/internal/models"
+	"api/internal/scripture"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type lookupResponse struct {
+	Refs    []string        `json:"refs"` // The requested passage, canonically
+	Sermons []*lookupSermon `json:"sermons"`
+}
+
+// lookupSermon is a sermon with notes that cover the passage
+type lookupSermon struct {
+	Sermon            *core.Record     `json:"sermon"`
+	KeyMatches        int              `json:"key_matches"`        // Notes whose key verse covers the passage
+	SupportingMatches int              `json:"supporting_matches"` // Notes that only use it as a relevant verse
+	Sections          []*lookupSection `json:"sections"`
+}
+
+// lookupSection is a note section that covers the passage
+type lookupSection struct {
+	DetailId       string        `json:"detail_id"`
+	Title          string        `json:"title"`
+	Order          int           `json:"order"`
+	KeyVerse       string        `json:"key_verse"`
+	RelevantVerses string        `json:"relevant_verses"`
+	Matches        []lookupMatch `json:"matches"`
+	key            bool
+}
+
+// lookupMatch is one of the section's references that overlaps the passage
+type lookupMatch struct {
+	Reference string `json:"reference"`
+	Kind      string `json:"kind"`
+}
+
+// scriptureLookup finds the sermons and note sections whose verses overlap the passage in the ref
+// query parameter. Sermons that covered it as a key verse come first, then those that only used it
+// in support, most recent first.
+func scriptureLookup(e *core.RequestEvent) error {
+	text := strings.TrimSpace(e.Request.URL.Query().Get("ref"))
+	if text == "" {
+		return e.BadRequestError("Missing ref.", nil)
+	}
+	refs, err := scripture.Parse(text)
+	if err != nil {
+		return e.BadRequestError(fmt.Sprintf("Invalid ref: %s.", err.Error()), nil)
+	}
+
+	// narrow it down to the right chapters in SQL, the verses are checked below
+	conditions := make([]dbx.Expression, len(refs))
+	for i, ref := range refs {
+		conditions[i] = dbx.And(
+			dbx.HashExp{"book": ref.Book},
+			dbx.NewExp(fmt.Sprintf("chapter <= {:end%d} AND end_chapter >= {:start%d}", i, i), dbx.Params{
+				fmt.Sprintf("start%d", i): ref.Chapter,
+				fmt.Sprintf("end%d", i):   ref.EndChapter,
+			}),
+		)
+	}
+	rows := []models.SermonVerseRef{}
+	err = e.App.DB().
+		Select("*").
+		From("sermon_verse_refs").
+		Where(dbx.Or(conditions...)).
+		All(&rows)
+	if err != nil {
+		return e.InternalServerError("Failed to search verse references.", err)
+	}
+
+	matches := []models.SermonVerseRef{}
+	sermonIds, detailIds := []string{}, []string{}
+	for _, row := range rows {
+		ref := scripture.Ref{Book: row.Book, Chapter: row.Chapter, Verse: row.Verse, EndChapter: row.EndChapter, EndVerse: row.EndVerse}
+		for _, wanted := range refs {
+			if ref.Overlaps(wanted) {
+				matches = append(matches, row)
+				sermonIds = append(sermonIds, row.SermonId)
+				detailIds = append(detailIds, row.DetailId)
+				break
+			}
+		}
+	}
+
+	sermons, err := visibleSermons(e, sermonIds)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+	details, err := e.App.FindRecordsByIds("sermon_details", detailIds)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermon notes.", err)
+	}
+	detailsById := map[string]*core.Record{}
+	for _, detail := range details {
+		detailsById[detail.Id] = detail
+	}
+
+	results := map[string]*lookupSermon{}
+	sections := map[string]*lookupSection{}
+	for _, match := range matches {
+		sermon, detail := sermons[match.SermonId], detailsById[match.DetailId]
+		if sermon == nil || detail == nil {
+			continue
+		}
+
+		result := results[sermon.Id]
+		if result == nil {
+			result = &lookupSermon{Sermon: sermon, Sections: []*lookupSection{}}
+			results[sermon.Id] = result
+		}
+
+		section := sections[detail.Id]
+		if section == nil {
+			section = &lookupSection{
+				DetailId:       detail.Id,
+				Title:          detail.GetString("title"),
+				Order:          detail.GetInt("order"),
+				KeyVerse:       detail.GetString("key_verse"),
+				RelevantVerses: detail.GetString("relevant_verses"),
+				Matches:        []lookupMatch{},
+			}
+			sections[detail.Id] = section
+			result.Sections = append(result.Sections, section)
+		}
+		section.Matches = append(section.Matches, lookupMatch{Reference: match.Reference, Kind: match.Kind})
+		section.key = section.key || match.Kind == models.VerseRefKey
+	}
+
+	response := lookupResponse{Refs: make([]string, len(refs)), Sermons: []*lookupSermon{}}
+	for i, ref := range refs {
+		response.Refs[i] = ref.String()
+	}
+	for _, result := range results {
+		for _, section := range result.Sections {
+			if section.key {
+				result.KeyMatches++
+			} else {
+				result.SupportingMatches++
+			}
+		}
+		sort.SliceStable(result.Sections, func(i, j int) bool {
+			a, b := result.Sections[i], result.Sections[j]
+			if a.key != b.key {
+				return a.key
+			}
+			return a.Order < b.Order
+		})
+		response.Sermons = append(response.Sermons, result)
+	}
+	sort.SliceStable(response.Sermons, func(i, j int) bool {
+		a, b := response.Sermons[i], response.Sermons[j]
+		if a.KeyMatches != b.KeyMatches {
+			return a.KeyMatches > b.KeyMatches
+		}
+		if a.SupportingMatches != b.SupportingMatches {
+			return a.SupportingMatches > b.SupportingMatches
+		}
+		if !a.Sermon.GetDateTime("date_given").Equal(b.Sermon.GetDateTime("date_given")) {
+			return a.Sermon.GetDateTime("date_given").After(b.Sermon.GetDateTime("date_given"))
+		}
+		return a.Sermon.Id < b.Sermon.Id
+	})
+
+	return e.JSON(http.StatusOK, response)
+}
+
+type textResponse struct {
+	Translation string         `json:"translation"`
+	Passages    []*textPassage `json:"passages"`
+}
+
+// textPassage is the text of one of the requested references
+type textPassage struct {
+	Reference string        `json:"reference"`
+	Text      string        `json:"text"` // The verses joined into a paragraph
+	Verses    []bible.Verse `json:"verses"`
+}
+
+// scriptureText returns the text of the passages in the ref query parameter from a bundled translation,
+// the translation query parameter or BIBLE_TRANSLATION by default
+func scriptureText(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+	text := strings.TrimSpace(query.Get("ref"))
+	if text == "" {
+		return e.BadRequestError("Missing ref.", nil)
+	}
+	refs, err := scripture.Parse(text)
+	if err != nil {
+		return e.BadRequestError(fmt.Sprintf("Invalid ref: %s.", err.Error()), nil)
+	}
+
+	id := query.Get("translation")
+	if id == "" {
+		id = bible.DefaultTranslation()
+	}
+	translation, err := bible.Load(id)
+	if errors.Is(err, bible.ErrNoTranslation) {
+		return e.NotFoundError(fmt.Sprintf("Translation %q is not available, the bundled translations are: %s.", id, strings.Join(bible.Translations(), ", ")), nil)
+	}
+	if err != nil {
+		return e.InternalServerError("Failed to load the translation.", err)
+	}
+
+	response := textResponse{Translation: translation.ID, Passages: make([]*textPassage, len(refs))}
+	for i, ref := range refs {
+		verses, err := translation.Passage(ref)
+		if err != nil {
+			return e.BadRequestError("Invalid ref.", err)
+		}
+
+		lines := make([]string, len(verses))
+		for j, verse := range verses {
+			lines[j] = verse.Text
+		}
+		response.Passages[i] = &textPassage{Reference: ref.String(), Text: strings.Join(lines, " "), Verses: verses}
+	}
+
+	return e.JSON(http.StatusOK, response)
+}
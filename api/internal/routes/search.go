@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"api/internal/fulltext"
+	"api/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type searchResponse struct {
+	Page       int           `json:"page"`
+	PerPage    int           `json:"perPage"`
+	TotalItems int           `json:"totalItems"`
+	TotalPages int           `json:"totalPages"`
+	Items      []*searchItem `json:"items"`
+}
+
+// searchItem is a matching sermon along with the best match within it
+type searchItem struct {
+	Sermon   *core.Record `json:"sermon"`
+	Source   string       `json:"source"`    // sermon, note, question or transcript
+	RecordId string       `json:"record_id"` // The record that matched
+	StartMs  int64        `json:"start_ms"`  // Where in the recording, for transcript matches
+	Snippet  string       `json:"snippet"`   // HTML, with the matches in <mark> tags
+	Matches  int          `json:"matches"`   // How many of the sermon's records matched
+}
+
+// searchSermons runs a full text search for the q query parameter over sermons, their notes, questions
+// and transcripts, paged with the page and perPage query parameters like the record list api. Viewers
+// only find complete sermons, admins also find ones still being analyzed.
+func searchSermons(e *core.RequestEvent) error {
+	text := strings.TrimSpace(e.Request.URL.Query().Get("q"))
+	if text == "" {
+		return e.BadRequestError("Missing q.", nil)
+	}
+
+	page, perPage, err := pageParams(e)
+	if err != nil {
+		return err
+	}
+	search := fulltext.Query{Text: text, Page: page, PerPage: perPage, IncludeIncomplete: seesIncomplete(e)}
+
+	results, err := fulltext.Search(e.App, search)
+	if err != nil {
+		return e.InternalServerError("Failed to search sermons.", err)
+	}
+
+	ids := make([]string, len(results.Items))
+	for i, hit := range results.Items {
+		ids[i] = hit.SermonId
+	}
+	sermons, err := visibleSermons(e, ids)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+
+	response := searchResponse{
+		Page:       results.Page,
+		PerPage:    results.PerPage,
+		TotalItems: results.TotalItems,
+		TotalPages: results.TotalPages,
+		Items:      []*searchItem{},
+	}
+	for _, hit := range results.Items {
+		sermon := sermons[hit.SermonId]
+		if sermon == nil {
+			continue
+		}
+		response.Items = append(response.Items, &searchItem{
+			Sermon:   sermon,
+			Source:   hit.Source,
+			RecordId: hit.RecordId,
+			StartMs:  hit.StartMs,
+			Snippet:  hit.Snippet,
+			Matches:  hit.Matches,
+		})
+	}
+
+	return e.JSON(http.StatusOK, response)
+}
+
+// pageParams reads the page and perPage query parameters, clamping perPage like the record list api
+func pageParams(e *core.RequestEvent) (page int, perPage int, err error) {
+	query := e.Request.URL.Query()
+	page, perPage = 1, fulltext.DefaultPerPage
+	if value := query.Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return 0, 0, e.BadRequestError("Invalid page.", nil)
+		}
+	}
+	if value := query.Get("perPage"); value != "" {
+		perPage, err = strconv.Atoi(value)
+		if err != nil || perPage < 1 {
+			return 0, 0, e.BadRequestError("Invalid perPage.", nil)
+		}
+	}
+	return page, min(perPage, fulltext.MaxPerPage), nil
+}
+
+// seesIncomplete reports whether the request can find sermons that haven't finished analysis
+func seesIncomplete(e *core.RequestEvent) bool {
+	return e.HasSuperuserAuth() || (e.Auth != nil && e.Auth.GetString("role") == models.UserRoleAdmin)
+}
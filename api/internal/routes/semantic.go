@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"api/internal/embeddings"
+	"api/internal/fulltext"
+	"api/internal/models"
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+)
+
+const (
+	// how long embedding a search query may take
+	queryEmbedTimeout = 30 * time.Second
+	// how many of the best keyword and semantic matches hybrid search fuses
+	hybridCandidates = fulltext.MaxPerPage
+	// reciprocal rank fusion constant, dampens how much the very top ranks dominate
+	rrfK = 60
+)
+
+// embedQuery computes the vector of a search query with the configured embedder
+func embedQuery(e *core.RequestEvent, text string) (model string, vector []float32, err error) {
+	embedder, err := embeddings.NewEmbedder()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(e.Request.Context(), queryEmbedTimeout)
+	defer cancel()
+	vectors, err := embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return "", nil, err
+	}
+	return embedder.Model(), vectors[0], nil
+}
+
+type semanticResponse struct {
+	Items []*semanticItem `json:"items"`
+}
+
+type semanticItem struct {
+	Sermon *core.Record `json:"sermon"`
+	embeddings.Match
+}
+
+// semanticSearch finds the sermons whose summary or notes are closest in meaning to the q query parameter,
+// up to limit (default 20) of them
+func semanticSearch(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+	text := strings.TrimSpace(query.Get("q"))
+	if text == "" {
+		return e.BadRequestError("Missing q.", nil)
+	}
+	limit := fulltext.DefaultPerPage
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return e.BadRequestError("Invalid limit.", nil)
+		}
+		limit = min(n, fulltext.MaxPerPage)
+	}
+
+	model, vector, err := embedQuery(e, text)
+	if err != nil {
+		e.App.Logger().Warn("Search: Error embedding query", "error", err.Error())
+		return e.Error(http.StatusServiceUnavailable, "Semantic search is unavailable.", nil)
+	}
+	matches, err := embeddings.Nearest(e.App, model, vector, limit, seesIncomplete(e))
+	if err != nil {
+		return e.InternalServerError("Failed to search sermons.", err)
+	}
+
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.SermonId
+	}
+	sermons, err := visibleSermons(e, ids)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+
+	response := semanticResponse{Items: []*semanticItem{}}
+	for _, match := range matches {
+		if sermon := sermons[match.SermonId]; sermon != nil {
+			response.Items = append(response.Items, &semanticItem{Sermon: sermon, Match: match})
+		}
+	}
+	return e.JSON(http.StatusOK, response)
+}
+
+type hybridResponse struct {
+	Page       int           `json:"page"`
+	PerPage    int           `json:"perPage"`
+	TotalItems int           `json:"totalItems"`
+	TotalPages int           `json:"totalPages"`
+	Semantic   bool          `json:"semantic"` // False when the embedder couldn't be reached and only keywords were used
+	Items      []*hybridItem `json:"items"`
+}
+
+// hybridItem is a sermon found by keywords, meaning or both
+type hybridItem struct {
+	Sermon   *core.Record      `json:"sermon"`
+	Score    float64           `json:"score"`
+	Keyword  *fulltext.Hit     `json:"keyword"`  // Best keyword match, null if the words weren't found
+	Semantic *embeddings.Match `json:"semantic"` // Closest summary or note, null if it wasn't among the nearest
+}
+
+// hybridSearch ranks sermons for the q query parameter by both keyword and semantic search, fusing the two
+// rankings so sermons that do well in either come first and those that do well in both come before them.
+// It's paged like searchSermons, over the best matches of each. When the embedder can't be reached it
+// falls back to keywords alone.
+func hybridSearch(e *core.RequestEvent) error {
+	text := strings.TrimSpace(e.Request.URL.Query().Get("q"))
+	if text == "" {
+		return e.BadRequestError("Missing q.", nil)
+	}
+	page, perPage, err := pageParams(e)
+	if err != nil {
+		return err
+	}
+	includeIncomplete := seesIncomplete(e)
+
+	keyword, err := fulltext.Search(e.App, fulltext.Query{Text: text, PerPage: hybridCandidates, IncludeIncomplete: includeIncomplete})
+	if err != nil {
+		return e.InternalServerError("Failed to search sermons.", err)
+	}
+
+	response := hybridResponse{Page: page, PerPage: perPage, Semantic: true, Items: []*hybridItem{}}
+	semantic := []embeddings.Match{}
+	model, vector, err := embedQuery(e, text)
+	if err != nil {
+		e.App.Logger().Warn("Search: Error embedding query, using keywords only", "error", err.Error())
+		response.Semantic = false
+	} else {
+		semantic, err = embeddings.Nearest(e.App, model, vector, hybridCandidates, includeIncomplete)
+		if err != nil {
+			return e.InternalServerError("Failed to search sermons.", err)
+		}
+	}
+
+	items := map[string]*hybridItem{}
+	item := func(sermonId string) *hybridItem {
+		if items[sermonId] == nil {
+			items[sermonId] = &hybridItem{}
+		}
+		return items[sermonId]
+	}
+	for i, hit := range keyword.Items {
+		found := item(hit.SermonId)
+		found.Keyword = &keyword.Items[i]
+		found.Score += 1.0 / float64(rrfK+i+1)
+	}
+	for i, match := range semantic {
+		found := item(match.SermonId)
+		found.Semantic = &semantic[i]
+		found.Score += 1.0 / float64(rrfK+i+1)
+	}
+
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if items[ids[i]].Score != items[ids[j]].Score {
+			return items[ids[i]].Score > items[ids[j]].Score
+		}
+		return ids[i] < ids[j]
+	})
+	response.TotalItems = len(ids)
+	response.TotalPages = (len(ids) + perPage - 1) / perPage
+
+	start := min((page-1)*perPage, len(ids))
+	ids = ids[start:min(start+perPage, len(ids))]
+	sermons, err := visibleSermons(e, ids)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+	for _, id := range ids {
+		if sermon := sermons[id]; sermon != nil {
+			items[id].Sermon = sermon
+			response.Items = append(response.Items, items[id])
+		}
+	}
+
+	return e.JSON(http.StatusOK, response)
+}
+
+// rebuildEmbeddings brings the vectors of every sermon up to date in the background, for sermons analyzed
+// before embeddings were configured or after switching models
+func rebuildEmbeddings(e *core.RequestEvent) error {
+	embedder, err := embeddings.NewEmbedder()
+	if err != nil {
+		return e.BadRequestError("Embeddings aren't configured: "+err.Error(), nil)
+	}
+
+	sermonIds := []string{}
+	err = e.App.DB().
+		Select("id").
+		From("sermons").
+		Where(dbx.Not(dbx.HashExp{"status": models.SermonStatusDeleted})).
+		Column(&sermonIds)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+
+	app := e.App
+	routine.FireAndForget(func() {
+		failed := 0
+		for _, sermonId := range sermonIds {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			err := embeddings.Sync(ctx, app, embedder, sermonId)
+			cancel()
+			if err != nil {
+				failed++
+				app.Logger().Warn("Embeddings: Error computing embeddings", "sermon", sermonId, "model", embedder.Model(), "error", err.Error())
+			}
+		}
+		app.Logger().Info("Embeddings: Rebuild complete", "sermons", len(sermonIds), "failed", failed, "model", embedder.Model())
+	})
+
+	return e.JSON(http.StatusAccepted, map[string]any{"sermons": len(sermonIds), "model": embedder.Model()})
+}
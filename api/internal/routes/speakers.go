@@ -0,0 +1,279 @@
+package routes
+
+import (
+	"api/internal/models"
+	"api/internal/scripture"
+	"api/internal/speakers"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// how many books and topics a speaker's statistics list
+const speakerTopCount = 10
+
+// speakerSermons is the condition for the sermons the request can see, the same as the sermons view rule
+func speakerSermons(e *core.RequestEvent) dbx.Expression {
+	if seesIncomplete(e) {
+		return dbx.Not(dbx.HashExp{"s.status": models.SermonStatusDeleted})
+	}
+	return dbx.HashExp{"s.status": models.SermonStatusComplete}
+}
+
+type speakersResponse struct {
+	Items []*speakerItem `json:"items"`
+}
+
+// speakerItem is a speaker along with how many of their sermons the request can see
+type speakerItem struct {
+	Speaker    *core.Record `json:"speaker"`
+	Sermons    int          `json:"sermons"`
+	FirstGiven string       `json:"first_given"` // YYYY-MM-DD, empty without dated sermons
+	LastGiven  string       `json:"last_given"`
+}
+
+type speakerCountRow struct {
+	SpeakerId  string         `db:"speaker_id"`
+	Sermons    int            `db:"sermons"`
+	FirstGiven types.DateTime `db:"first_given"`
+	LastGiven  types.DateTime `db:"last_given"`
+}
+
+// listSpeakers lists every speaker by name, with counts of their sermons
+func listSpeakers(e *core.RequestEvent) error {
+	records, err := e.App.FindRecordsByFilter(speakers.Collection, "", "name", 0, 0)
+	if err != nil {
+		return e.InternalServerError("Failed to load speakers.", err)
+	}
+
+	rows := []speakerCountRow{}
+	err = e.App.DB().
+		Select("s.speaker_id", "COUNT(*) AS sermons", "MIN(NULLIF(s.date_given, '')) AS first_given", "MAX(NULLIF(s.date_given, '')) AS last_given").
+		From("sermons s").
+		Where(dbx.NewExp("s.speaker_id != ''")).
+		AndWhere(speakerSermons(e)).
+		GroupBy("s.speaker_id").
+		All(&rows)
+	if err != nil {
+		return e.InternalServerError("Failed to count sermons.", err)
+	}
+	counts := map[string]speakerCountRow{}
+	for _, row := range rows {
+		counts[row.SpeakerId] = row
+	}
+
+	response := speakersResponse{Items: make([]*speakerItem, len(records))}
+	for i, record := range records {
+		count := counts[record.Id]
+		response.Items[i] = &speakerItem{
+			Speaker:    record,
+			Sermons:    count.Sermons,
+			FirstGiven: dateOnly(count.FirstGiven),
+			LastGiven:  dateOnly(count.LastGiven),
+		}
+	}
+	return e.JSON(http.StatusOK, response)
+}
+
+type speakerSermonsResponse struct {
+	Page       int            `json:"page"`
+	PerPage    int            `json:"perPage"`
+	TotalItems int            `json:"totalItems"`
+	TotalPages int            `json:"totalPages"`
+	Items      []*core.Record `json:"items"`
+}
+
+// listSpeakerSermons pages through the speaker's sermons, most recent first
+func listSpeakerSermons(e *core.RequestEvent) error {
+	speaker, err := e.App.FindRecordById(speakers.Collection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("Speaker not found.", err)
+	}
+	page, perPage, err := pageParams(e)
+	if err != nil {
+		return err
+	}
+
+	where := dbx.And(dbx.HashExp{"s.speaker_id": speaker.Id}, speakerSermons(e))
+	var total int
+	err = e.App.DB().Select("COUNT(*)").From("sermons s").Where(where).Row(&total)
+	if err != nil {
+		return e.InternalServerError("Failed to count sermons.", err)
+	}
+
+	ids := []string{}
+	err = e.App.DB().
+		Select("s.id").
+		From("sermons s").
+		Where(where).
+		OrderBy("s.date_given DESC", "s.created DESC").
+		Limit(int64(perPage)).
+		Offset(int64((page - 1) * perPage)).
+		Column(&ids)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+	sermons, err := visibleSermons(e, ids)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+
+	response := speakerSermonsResponse{
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: total,
+		TotalPages: (total + perPage - 1) / perPage,
+		Items:      []*core.Record{},
+	}
+	for _, id := range ids {
+		if sermon := sermons[id]; sermon != nil {
+			response.Items = append(response.Items, sermon)
+		}
+	}
+	return e.JSON(http.StatusOK, response)
+}
+
+type speakerStatsResponse struct {
+	Speaker       *core.Record  `json:"speaker"`
+	Sermons       int           `json:"sermons"`
+	FirstGiven    string        `json:"first_given"`    // YYYY-MM-DD, empty without dated sermons
+	LastGiven     string        `json:"last_given"`     //
+	TotalDuration float64       `json:"total_duration"` // Seconds of analyzed audio
+	ByYear        []*yearCount  `json:"by_year"`        // Dated sermons per year, oldest first
+	TopBooks      []*bookCount  `json:"top_books"`      // Books the speaker took key verses from most
+	TopTopics     []*topicCount `json:"top_topics"`
+}
+
+// speakerSermonRow is what the statistics need of each sermon
+type speakerSermonRow struct {
+	Id            string                  `db:"id"`
+	DateGiven     types.DateTime          `db:"date_given"`
+	Topics        types.JSONArray[string] `db:"topics"`
+	AudioDuration float64                 `db:"audio_duration"`
+}
+
+type yearCount struct {
+	Year    int `json:"year"`
+	Sermons int `json:"sermons"`
+}
+
+type bookCount struct {
+	Book    string `json:"book" db:"book"`
+	Name    string `json:"name" db:"-"`
+	Sermons int    `json:"sermons" db:"sermons"`
+}
+
+type topicCount struct {
+	Topic   string `json:"topic"`
+	Sermons int    `json:"sermons"`
+}
+
+// speakerStats summarizes the speaker's sermons: when they were given, how much was preached, and the
+// books and topics they came back to most
+func speakerStats(e *core.RequestEvent) error {
+	speaker, err := e.App.FindRecordById(speakers.Collection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("Speaker not found.", err)
+	}
+	where := dbx.And(dbx.HashExp{"s.speaker_id": speaker.Id}, speakerSermons(e))
+
+	sermons := []speakerSermonRow{}
+	err = e.App.DB().
+		Select("s.id", "s.date_given", "s.topics", "s.audio_duration").
+		From("sermons s").
+		Where(where).
+		All(&sermons)
+	if err != nil {
+		return e.InternalServerError("Failed to load sermons.", err)
+	}
+
+	response := speakerStatsResponse{Speaker: speaker, Sermons: len(sermons)}
+	years := map[int]int{}
+	topics := map[string]int{}
+	var first, last time.Time
+	for _, sermon := range sermons {
+		response.TotalDuration += sermon.AudioDuration
+		for _, topic := range sermon.Topics {
+			topics[topic]++
+		}
+		if sermon.DateGiven.IsZero() {
+			continue
+		}
+		date := sermon.DateGiven.Time()
+		years[date.Year()]++
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if date.After(last) {
+			last = date
+		}
+	}
+	if !first.IsZero() {
+		response.FirstGiven = first.Format(time.DateOnly)
+		response.LastGiven = last.Format(time.DateOnly)
+	}
+
+	response.ByYear = []*yearCount{}
+	for year, count := range years {
+		response.ByYear = append(response.ByYear, &yearCount{Year: year, Sermons: count})
+	}
+	sort.Slice(response.ByYear, func(i, j int) bool {
+		return response.ByYear[i].Year < response.ByYear[j].Year
+	})
+
+	response.TopTopics = []*topicCount{}
+	for topic, count := range topics {
+		response.TopTopics = append(response.TopTopics, &topicCount{Topic: topic, Sermons: count})
+	}
+	sort.Slice(response.TopTopics, func(i, j int) bool {
+		a, b := response.TopTopics[i], response.TopTopics[j]
+		if a.Sermons != b.Sermons {
+			return a.Sermons > b.Sermons
+		}
+		return a.Topic < b.Topic
+	})
+	response.TopTopics = response.TopTopics[:min(len(response.TopTopics), speakerTopCount)]
+
+	books := []bookCount{}
+	err = e.App.DB().
+		Select("r.book", "COUNT(DISTINCT r.sermon_id) AS sermons").
+		From("sermon_verse_refs r").
+		InnerJoin("sermons s", dbx.NewExp("s.id = r.sermon_id")).
+		Where(where).
+		AndWhere(dbx.HashExp{"r.kind": models.VerseRefKey}).
+		GroupBy("r.book").
+		All(&books)
+	if err != nil {
+		return e.InternalServerError("Failed to load verse references.", err)
+	}
+	response.TopBooks = []*bookCount{}
+	for _, count := range books {
+		if book, ok := scripture.BookByID(count.Book); ok {
+			response.TopBooks = append(response.TopBooks, &bookCount{Book: book.ID, Name: book.Name, Sermons: count.Sermons})
+		}
+	}
+	sort.Slice(response.TopBooks, func(i, j int) bool {
+		a, b := response.TopBooks[i], response.TopBooks[j]
+		if a.Sermons != b.Sermons {
+			return a.Sermons > b.Sermons
+		}
+		bookA, _ := scripture.BookByID(a.Book)
+		bookB, _ := scripture.BookByID(b.Book)
+		return bookA.Index() < bookB.Index()
+	})
+	response.TopBooks = response.TopBooks[:min(len(response.TopBooks), speakerTopCount)]
+
+	return e.JSON(http.StatusOK, response)
+}
+
+// dateOnly formats a date as YYYY-MM-DD, empty when it isn't set
+func dateOnly(date types.DateTime) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Time().Format(time.DateOnly)
+}
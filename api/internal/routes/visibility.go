@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"api/internal/models"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// visibleSermons loads the sermons the request is allowed to view through the sermons collection's
+// view rule, keyed by id. Deleted sermons are always left out.
+func visibleSermons(e *core.RequestEvent, ids []string) (map[string]*core.Record, error) {
+	sermons := map[string]*core.Record{}
+	if len(ids) == 0 {
+		return sermons, nil
+	}
+
+	collection, err := e.App.FindCollectionByNameOrId("sermons")
+	if err != nil {
+		return nil, err
+	}
+	info, err := e.RequestInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	query := e.App.RecordQuery(collection).
+		AndWhere(dbx.In(collection.Name+".id", values...)).
+		AndWhere(dbx.Not(dbx.HashExp{collection.Name + ".status": models.SermonStatusDeleted}))
+
+	if !info.HasSuperuserAuth() {
+		if collection.ViewRule == nil {
+			return sermons, nil
+		}
+		if *collection.ViewRule != "" {
+			resolver := core.NewRecordFieldResolver(e.App, collection, info, true)
+			expr, err := search.FilterData(*collection.ViewRule).BuildExpr(resolver)
+			if err != nil {
+				return nil, err
+			}
+			resolver.UpdateQuery(query)
+			query.AndWhere(expr)
+		}
+	}
+
+	records := []*core.Record{}
+	if err := query.All(&records); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		sermons[record.Id] = record
+	}
+	return sermons, nil
+}
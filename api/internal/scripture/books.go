@@ -0,0 +1,185 @@
+package scripture
+
+import (
+	"strings"
+)
+
+// Book is a book of the (66 book, Protestant) Bible, with the KJV's chapter and verse counts
+type Book struct {
+	ID      string   // USFM book code, e.g. MAT or 1CO
+	Name    string   // full name, e.g. Matthew
+	Abbrev  string   // canonical abbreviation, e.g. Matt
+	Aliases []string // other abbreviations, in normalized form (see normalizeName)
+	Verses  []int    // number of verses in each chapter
+}
+
+// Chapters is how many chapters the book has
+func (b *Book) Chapters() int {
+	return len(b.Verses)
+}
+
+// VersesIn is how many verses a chapter of the book has, 0 if it doesn't have that chapter
+func (b *Book) VersesIn(chapter int) int {
+	if chapter < 1 || chapter > len(b.Verses) {
+		return 0
+	}
+	return b.Verses[chapter-1]
+}
+
+// Index is the book's position in canonical order, starting at 0 for Genesis
+func (b *Book) Index() int {
+	return bookIndex[b.ID]
+}
+
+// IsOldTestament reports whether the book comes before Matthew
+func (b *Book) IsOldTestament() bool {
+	return b.Index() < bookIndex["MAT"]
+}
+
+var books = []Book{
+	// Old Testament
+	{ID: "GEN", Name: "Genesis", Abbrev: "Gen", Aliases: []string{"ge", "gn"}, Verses: []int{31, 25, 24, 26, 32, 22, 24, 22, 29, 32, 32, 20, 18, 24, 21, 16, 27, 33, 38, 18, 34, 24, 20, 67, 34, 35, 46, 22, 35, 43, 55, 32, 20, 31, 29, 43, 36, 30, 23, 23, 57, 38, 34, 34, 28, 34, 31, 22, 33, 26}},
+	{ID: "EXO", Name: "Exodus", Abbrev: "Exod", Aliases: []string{"ex", "exo"}, Verses: []int{22, 25, 22, 31, 23, 30, 25, 32, 35, 29, 10, 51, 22, 31, 27, 36, 16, 27, 25, 26, 36, 31, 33, 18, 40, 37, 21, 43, 46, 38, 18, 35, 23, 35, 35, 38, 29, 31, 43, 38}},
+	{ID: "LEV", Name: "Leviticus", Abbrev: "Lev", Aliases: []string{"le", "lv"}, Verses: []int{17, 16, 17, 35, 19, 30, 38, 36, 24, 20, 47, 8, 59, 57, 33, 34, 16, 30, 37, 27, 24, 33, 44, 23, 55, 46, 34}},
+	{ID: "NUM", Name: "Numbers", Abbrev: "Num", Aliases: []string{"nu", "nm", "nb"}, Verses: []int{54, 34, 51, 49, 31, 27, 89, 26, 23, 36, 35, 16, 33, 45, 41, 50, 13, 32, 22, 29, 35, 41, 30, 25, 18, 65, 23, 31, 40, 16, 54, 42, 56, 29, 34, 13}},
+	{ID: "DEU", Name: "Deuteronomy", Abbrev: "Deut", Aliases: []string{"dt", "deu"}, Verses: []int{46, 37, 29, 49, 33, 25, 26, 20, 29, 22, 32, 32, 18, 29, 23, 22, 20, 22, 21, 20, 23, 30, 25, 22, 19, 19, 26, 68, 29, 20, 30, 52, 29, 12}},
+	{ID: "JOS", Name: "Joshua", Abbrev: "Josh", Aliases: []string{"jos", "jsh"}, Verses: []int{18, 24, 17, 24, 15, 27, 26, 35, 27, 43, 23, 24, 33, 15, 63, 10, 18, 28, 51, 9, 45, 34, 16, 33}},
+	{ID: "JDG", Name: "Judges", Abbrev: "Judg", Aliases: []string{"jdg", "jg", "jdgs"}, Verses: []int{36, 23, 31, 24, 31, 40, 25, 35, 57, 18, 40, 15, 25, 20, 20, 31, 13, 31, 30, 48, 25}},
+	{ID: "RUT", Name: "Ruth", Abbrev: "Ruth", Aliases: []string{"ru", "rth"}, Verses: []int{22, 23, 18, 22}},
+	{ID: "1SA", Name: "1 Samuel", Abbrev: "1 Sam", Aliases: []string{"1sa", "1sm"}, Verses: []int{28, 36, 21, 22, 12, 21, 17, 22, 27, 27, 15, 25, 23, 52, 35, 23, 58, 30, 24, 42, 15, 23, 29, 22, 44, 25, 12, 25, 11, 31, 13}},
+	{ID: "2SA", Name: "2 Samuel", Abbrev: "2 Sam", Aliases: []string{"2sa", "2sm"}, Verses: []int{27, 32, 39, 12, 25, 23, 29, 18, 13, 19, 27, 31, 39, 33, 37, 23, 29, 33, 43, 26, 22, 51, 39, 25}},
+	{ID: "1KI", Name: "1 Kings", Abbrev: "1 Kgs", Aliases: []string{"1ki", "1kg", "1kin"}, Verses: []int{53, 46, 28, 34, 18, 38, 51, 66, 28, 29, 43, 33, 34, 31, 34, 34, 24, 46, 21, 43, 29, 53}},
+	{ID: "2KI", Name: "2 Kings", Abbrev: "2 Kgs", Aliases: []string{"2ki", "2kg", "2kin"}, Verses: []int{18, 25, 27, 44, 27, 33, 20, 29, 37, 36, 21, 21, 25, 29, 38, 20, 41, 37, 37, 21, 26, 20, 37, 20, 30}},
+	{ID: "1CH", Name: "1 Chronicles", Abbrev: "1 Chr", Aliases: []string{"1ch", "1chron"}, Verses: []int{54, 55, 24, 43, 26, 81, 40, 40, 44, 14, 47, 40, 14, 17, 29, 43, 27, 17, 19, 8, 30, 19, 32, 31, 31, 32, 34, 21, 30}},
+	{ID: "2CH", Name: "2 Chronicles", Abbrev: "2 Chr", Aliases: []string{"2ch", "2chron"}, Verses: []int{17, 18, 17, 22, 14, 42, 22, 18, 31, 19, 23, 16, 22, 15, 19, 14, 19, 34, 11, 37, 20, 12, 21, 27, 28, 23, 9, 27, 36, 27, 21, 33, 25, 33, 27, 23}},
+	{ID: "EZR", Name: "Ezra", Abbrev: "Ezra", Aliases: []string{"ezr"}, Verses: []int{11, 70, 13, 24, 17, 22, 28, 36, 15, 44}},
+	{ID: "NEH", Name: "Nehemiah", Abbrev: "Neh", Aliases: []string{"ne"}, Verses: []int{11, 20, 32, 23, 19, 19, 73, 18, 38, 39, 36, 47, 31}},
+	{ID: "EST", Name: "Esther", Abbrev: "Esth", Aliases: []string{"es", "est"}, Verses: []int{22, 23, 15, 17, 14, 14, 10, 17, 32, 3}},
+	{ID: "JOB", Name: "Job", Abbrev: "Job", Aliases: []string{"jb"}, Verses: []int{22, 13, 26, 21, 27, 30, 21, 22, 35, 22, 20, 25, 28, 22, 35, 22, 16, 21, 29, 29, 34, 30, 17, 25, 6, 14, 23, 28, 25, 31, 40, 22, 33, 37, 16, 33, 24, 41, 30, 24, 34, 17}},
+	{ID: "PSA", Name: "Psalms", Abbrev: "Ps", Aliases: []string{"psa", "psm", "pss", "psalm"}, Verses: []int{6, 12, 8, 8, 12, 10, 17, 9, 20, 18, 7, 8, 6, 7, 5, 11, 15, 50, 14, 9, 13, 31, 6, 10, 22, 12, 14, 9, 11, 12, 24, 11, 22, 22, 28, 12, 40, 22, 13, 17, 13, 11, 5, 26, 17, 11, 9, 14, 20, 23, 19, 9, 6, 7, 23, 13, 11, 11, 17, 12, 8, 12, 11, 10, 13, 20, 7, 35, 36, 5, 24, 20, 28, 23, 10, 12, 20, 72, 13, 19, 16, 8, 18, 12, 13, 17, 7, 18, 52, 17, 16, 15, 5, 23, 11, 13, 12, 9, 9, 5, 8, 28, 22, 35, 45, 48, 43, 13, 31, 7, 10, 10, 9, 8, 18, 19, 2, 29, 176, 7, 8, 9, 4, 8, 5, 6, 5, 6, 8, 8, 3, 18, 3, 3, 21, 26, 9, 8, 24, 13, 10, 7, 12, 15, 21, 10, 20, 14, 9, 6}},
+	{ID: "PRO", Name: "Proverbs", Abbrev: "Prov", Aliases: []string{"pr", "prv", "pro"}, Verses: []int{33, 22, 35, 27, 23, 35, 27, 36, 18, 32, 31, 28, 25, 35, 33, 33, 28, 24, 29, 30, 31, 29, 35, 34, 28, 28, 27, 28, 27, 33, 31}},
+	{ID: "ECC", Name: "Ecclesiastes", Abbrev: "Eccl", Aliases: []string{"ec", "ecc", "eccles", "qoh", "qoheleth"}, Verses: []int{18, 26, 22, 16, 20, 12, 29, 17, 18, 20, 10, 14}},
+	{ID: "SNG", Name: "Song of Songs", Abbrev: "Song", Aliases: []string{"sng", "sos", "songofsolomon", "canticles"}, Verses: []int{17, 17, 11, 16, 16, 13, 13, 14}},
+	{ID: "ISA", Name: "Isaiah", Abbrev: "Isa", Aliases: []string{"is"}, Verses: []int{31, 22, 26, 6, 30, 13, 25, 22, 21, 34, 16, 6, 22, 32, 9, 14, 14, 7, 25, 6, 17, 25, 18, 23, 12, 21, 13, 29, 24, 33, 9, 20, 24, 17, 10, 22, 38, 22, 8, 31, 29, 25, 28, 28, 25, 13, 15, 22, 26, 11, 23, 15, 12, 17, 13, 12, 21, 14, 21, 22, 11, 12, 19, 12, 25, 24}},
+	{ID: "JER", Name: "Jeremiah", Abbrev: "Jer", Aliases: []string{"je", "jr"}, Verses: []int{19, 37, 25, 31, 31, 30, 34, 22, 26, 25, 23, 17, 27, 22, 21, 21, 27, 23, 15, 18, 14, 30, 40, 10, 38, 24, 22, 17, 32, 24, 40, 44, 26, 22, 19, 32, 21, 28, 18, 16, 18, 22, 13, 30, 5, 28, 7, 47, 39, 46, 64, 34}},
+	{ID: "LAM", Name: "Lamentations", Abbrev: "Lam", Aliases: []string{"la"}, Verses: []int{22, 22, 66, 22, 22}},
+	{ID: "EZK", Name: "Ezekiel", Abbrev: "Ezek", Aliases: []string{"eze", "ezk"}, Verses: []int{28, 10, 27, 17, 17, 14, 27, 18, 11, 22, 25, 28, 23, 23, 8, 63, 24, 32, 14, 49, 32, 31, 49, 27, 17, 21, 36, 26, 21, 26, 18, 32, 33, 31, 15, 38, 28, 23, 29, 49, 26, 20, 27, 31, 25, 24, 23, 35}},
+	{ID: "DAN", Name: "Daniel", Abbrev: "Dan", Aliases: []string{"da", "dn"}, Verses: []int{21, 49, 30, 37, 31, 28, 28, 27, 27, 21, 45, 13}},
+	{ID: "HOS", Name: "Hosea", Abbrev: "Hos", Aliases: []string{"ho"}, Verses: []int{11, 23, 5, 19, 15, 11, 16, 14, 17, 15, 12, 14, 16, 9}},
+	{ID: "JOL", Name: "Joel", Abbrev: "Joel", Aliases: []string{"jl", "joe"}, Verses: []int{20, 32, 21}},
+	{ID: "AMO", Name: "Amos", Abbrev: "Amos", Aliases: []string{"am"}, Verses: []int{15, 16, 15, 13, 27, 14, 17, 14, 15}},
+	{ID: "OBA", Name: "Obadiah", Abbrev: "Obad", Aliases: []string{"ob", "oba"}, Verses: []int{21}},
+	{ID: "JON", Name: "Jonah", Abbrev: "Jonah", Aliases: []string{"jnh", "jon"}, Verses: []int{17, 10, 10, 11}},
+	{ID: "MIC", Name: "Micah", Abbrev: "Mic", Aliases: []string{"mi"}, Verses: []int{16, 13, 12, 13, 15, 16, 20}},
+	{ID: "NAM", Name: "Nahum", Abbrev: "Nah", Aliases: []string{"na"}, Verses: []int{15, 13, 19}},
+	{ID: "HAB", Name: "Habakkuk", Abbrev: "Hab", Aliases: []string{"hb"}, Verses: []int{17, 20, 19}},
+	{ID: "ZEP", Name: "Zephaniah", Abbrev: "Zeph", Aliases: []string{"zep", "zp"}, Verses: []int{18, 15, 20}},
+	{ID: "HAG", Name: "Haggai", Abbrev: "Hag", Aliases: []string{"hg"}, Verses: []int{15, 23}},
+	{ID: "ZEC", Name: "Zechariah", Abbrev: "Zech", Aliases: []string{"zec", "zc"}, Verses: []int{21, 13, 10, 14, 11, 15, 14, 23, 17, 12, 17, 14, 9, 21}},
+	{ID: "MAL", Name: "Malachi", Abbrev: "Mal", Aliases: []string{"ml"}, Verses: []int{14, 17, 18, 6}},
+
+	// New Testament
+	{ID: "MAT", Name: "Matthew", Abbrev: "Matt", Aliases: []string{"mt", "mat"}, Verses: []int{25, 23, 17, 25, 48, 34, 29, 34, 38, 42, 30, 50, 58, 36, 39, 28, 27, 35, 30, 34, 46, 46, 39, 51, 46, 75, 66, 20}},
+	{ID: "MRK", Name: "Mark", Abbrev: "Mark", Aliases: []string{"mk", "mrk", "mr"}, Verses: []int{45, 28, 35, 41, 43, 56, 37, 38, 50, 52, 33, 44, 37, 72, 47, 20}},
+	{ID: "LUK", Name: "Luke", Abbrev: "Luke", Aliases: []string{"lk", "lu"}, Verses: []int{80, 52, 38, 44, 39, 49, 50, 56, 62, 42, 54, 59, 35, 35, 32, 31, 37, 43, 48, 47, 38, 71, 56, 53}},
+	{ID: "JHN", Name: "John", Abbrev: "John", Aliases: []string{"jn", "jhn", "joh"}, Verses: []int{51, 25, 36, 54, 47, 71, 53, 59, 41, 42, 57, 50, 38, 31, 27, 33, 26, 40, 42, 31, 25}},
+	{ID: "ACT", Name: "Acts", Abbrev: "Acts", Aliases: []string{"ac", "act"}, Verses: []int{26, 47, 26, 37, 42, 15, 60, 40, 43, 48, 30, 25, 52, 28, 41, 40, 34, 28, 41, 38, 40, 30, 35, 27, 27, 32, 44, 31}},
+	{ID: "ROM", Name: "Romans", Abbrev: "Rom", Aliases: []string{"ro", "rm"}, Verses: []int{32, 29, 31, 25, 21, 23, 25, 39, 33, 21, 36, 21, 14, 23, 33, 27}},
+	{ID: "1CO", Name: "1 Corinthians", Abbrev: "1 Cor", Aliases: []string{"1co"}, Verses: []int{31, 16, 23, 21, 13, 20, 40, 13, 27, 33, 34, 31, 13, 40, 58, 24}},
+	{ID: "2CO", Name: "2 Corinthians", Abbrev: "2 Cor", Aliases: []string{"2co"}, Verses: []int{24, 17, 18, 18, 21, 18, 16, 24, 15, 18, 33, 21, 14}},
+	{ID: "GAL", Name: "Galatians", Abbrev: "Gal", Aliases: []string{"ga"}, Verses: []int{24, 21, 29, 31, 26, 18}},
+	{ID: "EPH", Name: "Ephesians", Abbrev: "Eph", Aliases: []string{"ephes"}, Verses: []int{23, 22, 21, 32, 33, 24}},
+	{ID: "PHP", Name: "Philippians", Abbrev: "Phil", Aliases: []string{"php", "pp"}, Verses: []int{30, 30, 21, 23}},
+	{ID: "COL", Name: "Colossians", Abbrev: "Col", Aliases: []string{"col"}, Verses: []int{29, 23, 25, 18}},
+	{ID: "1TH", Name: "1 Thessalonians", Abbrev: "1 Thess", Aliases: []string{"1th", "1thes"}, Verses: []int{10, 20, 13, 18, 28}},
+	{ID: "2TH", Name: "2 Thessalonians", Abbrev: "2 Thess", Aliases: []string{"2th", "2thes"}, Verses: []int{12, 17, 18}},
+	{ID: "1TI", Name: "1 Timothy", Abbrev: "1 Tim", Aliases: []string{"1ti"}, Verses: []int{20, 15, 16, 16, 25, 21}},
+	{ID: "2TI", Name: "2 Timothy", Abbrev: "2 Tim", Aliases: []string{"2ti"}, Verses: []int{18, 26, 17, 22}},
+	{ID: "TIT", Name: "Titus", Abbrev: "Titus", Aliases: []string{"ti"}, Verses: []int{16, 15, 15}},
+	{ID: "PHM", Name: "Philemon", Abbrev: "Phlm", Aliases: []string{"phm", "philem"}, Verses: []int{25}},
+	{ID: "HEB", Name: "Hebrews", Abbrev: "Heb", Aliases: []string{"he"}, Verses: []int{14, 18, 19, 16, 14, 20, 28, 13, 28, 39, 40, 29, 25}},
+	{ID: "JAS", Name: "James", Abbrev: "Jas", Aliases: []string{"jm"}, Verses: []int{27, 26, 18, 17, 20}},
+	{ID: "1PE", Name: "1 Peter", Abbrev: "1 Pet", Aliases: []string{"1pe", "1pt"}, Verses: []int{25, 25, 22, 19, 14}},
+	{ID: "2PE", Name: "2 Peter", Abbrev: "2 Pet", Aliases: []string{"2pe", "2pt"}, Verses: []int{21, 22, 18}},
+	{ID: "1JN", Name: "1 John", Abbrev: "1 John", Aliases: []string{"1jn", "1jo", "1jhn"}, Verses: []int{10, 29, 24, 21, 21}},
+	{ID: "2JN", Name: "2 John", Abbrev: "2 John", Aliases: []string{"2jn", "2jo", "2jhn"}, Verses: []int{13}},
+	{ID: "3JN", Name: "3 John", Abbrev: "3 John", Aliases: []string{"3jn", "3jo", "3jhn"}, Verses: []int{14}},
+	{ID: "JUD", Name: "Jude", Abbrev: "Jude", Aliases: []string{"jud", "jd"}, Verses: []int{25}},
+	{ID: "REV", Name: "Revelation", Abbrev: "Rev", Aliases: []string{"re", "rv", "revelations", "apocalypse"}, Verses: []int{20, 29, 22, 11, 14, 17, 17, 13, 21, 11, 19, 17, 18, 20, 8, 21, 18, 24, 21, 15, 27, 21}},
+}
+
+var (
+	bookIndex = map[string]int{}
+	bookNames = map[string]*Book{}
+)
+
+func init() {
+	for i := range books {
+		book := &books[i]
+		bookIndex[book.ID] = i
+		for _, name := range append([]string{book.ID, book.Name, book.Abbrev}, book.Aliases...) {
+			bookNames[normalizeName(name)] = book
+		}
+	}
+}
+
+// Books returns every book in canonical order
+func Books() []*Book {
+	all := make([]*Book, len(books))
+	for i := range books {
+		all[i] = &books[i]
+	}
+	return all
+}
+
+// BookByID finds a book by its USFM code
+func BookByID(id string) (*Book, bool) {
+	i, ok := bookIndex[strings.ToUpper(id)]
+	if !ok {
+		return nil, false
+	}
+	return &books[i], true
+}
+
+// LookupBook finds a book by its name, ID or any common abbreviation, ignoring case, spacing and
+// periods. Numbered books can be written "1 John", "1John", "I John" or "First John". Failing that,
+// a name that starts the full name of exactly one book, like "Deuter", finds that book.
+func LookupBook(name string) (*Book, bool) {
+	key := normalizeName(name)
+	if key == "" {
+		return nil, false
+	}
+	if book, ok := bookNames[key]; ok {
+		return book, true
+	}
+
+	var found *Book
+	for i := range books {
+		if strings.HasPrefix(normalizeName(books[i].Name), key) {
+			if found != nil {
+				return nil, false
+			}
+			found = &books[i]
+		}
+	}
+	return found, found != nil
+}
+
+// numberWords are the ways the number of a numbered book gets written
+var numberWords = map[string]string{
+	"i": "1", "ii": "2", "iii": "3",
+	"first": "1", "second": "2", "third": "3",
+	"1st": "1", "2nd": "2", "3rd": "3",
+}
+
+// normalizeName lowercases a book name and strips spaces and periods, with any leading
+// number written as a digit: "I Sam." becomes "1sam"
+func normalizeName(name string) string {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(name, ".", " ")))
+	if len(words) > 1 {
+		if n, ok := numberWords[words[0]]; ok {
+			words[0] = n
+		}
+	}
+	return strings.Join(words, "")
+}
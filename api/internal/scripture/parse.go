@@ -0,0 +1,213 @@
+package scripture
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnknownBook = errors.New("unknown book")
+	ErrMalformed   = errors.New("malformed reference")
+	ErrOutOfRange  = errors.New("no such chapter or verse")
+	ErrBackwards   = errors.New("range ends before it starts")
+)
+
+// ParseError is a reference that couldn't be understood
+type ParseError struct {
+	Text string // the part of the input it came from
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%q: %s", e.Text, e.Err.Error())
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors is every reference in the input that couldn't be understood
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+var (
+	// a single point, either "chapter" or "chapter:verse", or just "verse" depending on context
+	pointPattern = regexp.MustCompile(`^([1-9]\d{0,2})(?::([1-9]\d{0,2}))?$`)
+	// the a/b halves of a verse, e.g. 5:3a, which we round out to the whole verse
+	partialVersePattern = regexp.MustCompile(`(\d)[abc]([-,]|$)`)
+	dashReplacer        = strings.NewReplacer("–", "-", "—", "-", "‐", "-", "‑", "-")
+)
+
+// Parse reads free text references like "Matt 5:4-9", "Gen 1:1-3|Num 6:12", "John 3:16, 18; 4:1-2"
+// or "Ps 23-24". References are separated by pipes, semicolons or new lines, and those without
+// a book name continue the previous book. Within a reference, commas list more verses in the
+// same chapter, or more chapters if the reference started with whole chapters.
+//
+// Parse returns every reference it could understand, and a ParseErrors listing the ones it couldn't.
+func Parse(text string) ([]Ref, error) {
+	refs := []Ref{}
+	var errs ParseErrors
+
+	var book *Book
+	parts := strings.FieldsFunc(text, func(r rune) bool {
+		return r == '|' || r == ';' || r == '\n'
+	})
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, passage := splitBook(part)
+		if name != "" {
+			found, ok := LookupBook(name)
+			if !ok {
+				errs = append(errs, &ParseError{Text: part, Err: fmt.Errorf("%w %q", ErrUnknownBook, strings.TrimSpace(name))})
+				book = nil
+				continue
+			}
+			book = found
+		}
+		if book == nil {
+			errs = append(errs, &ParseError{Text: part, Err: fmt.Errorf("%w: no book", ErrMalformed)})
+			continue
+		}
+
+		parsed, err := parsePassage(book, passage)
+		for _, err := range err {
+			errs = append(errs, &ParseError{Text: part, Err: err})
+		}
+		refs = append(refs, parsed...)
+	}
+
+	if len(errs) > 0 {
+		return refs, errs
+	}
+	return refs, nil
+}
+
+// splitBook splits a reference into the book name and the passage, which starts at the first
+// digit after the name. Either may be empty.
+func splitBook(text string) (name string, passage string) {
+	seenLetter := false
+	for i, r := range text {
+		if unicode.IsLetter(r) {
+			seenLetter = true
+		} else if seenLetter && unicode.IsDigit(r) {
+			return text[:i], text[i:]
+		}
+	}
+	if seenLetter {
+		return text, ""
+	}
+	return "", text
+}
+
+// parsePassage parses the chapters and verses of a reference to book, which may be a comma list
+func parsePassage(book *Book, passage string) ([]Ref, []error) {
+	passage = strings.Join(strings.Fields(dashReplacer.Replace(passage)), "")
+	passage = strings.TrimSuffix(passage, ".")
+	passage = partialVersePattern.ReplaceAllString(passage, "$1$2")
+
+	if passage == "" {
+		// a book with a single chapter can be cited whole
+		if book.Chapters() == 1 {
+			return []Ref{{Book: book.ID, Chapter: 1, EndChapter: 1}}, nil
+		}
+		return nil, []error{fmt.Errorf("%w: no chapter", ErrMalformed)}
+	}
+
+	// books with a single chapter are cited by verse, e.g. Jude 3
+	chapter, inVerses := 0, false
+	if book.Chapters() == 1 {
+		chapter, inVerses = 1, true
+	}
+
+	refs := []Ref{}
+	var errs []error
+	for _, item := range strings.Split(passage, ",") {
+		start, end, hasEnd := strings.Cut(item, "-")
+
+		startA, startB, startColon, ok := parsePoint(start)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrMalformed, item))
+			continue
+		}
+
+		ref := Ref{Book: book.ID}
+		switch {
+		case startColon:
+			ref.Chapter, ref.Verse = startA, startB
+		case inVerses:
+			ref.Chapter, ref.Verse = chapter, startA
+		default:
+			ref.Chapter = startA
+		}
+
+		if hasEnd {
+			endA, endB, endColon, ok := parsePoint(end)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%w: %q", ErrMalformed, item))
+				continue
+			}
+			switch {
+			case endColon:
+				ref.EndChapter, ref.EndVerse = endA, endB
+				// "Gen 1-2:3" starts at the beginning of chapter 1
+				if ref.Verse == 0 {
+					ref.Verse = 1
+				}
+			case ref.Verse != 0:
+				ref.EndChapter, ref.EndVerse = ref.Chapter, endA
+			default:
+				ref.EndChapter = endA
+			}
+		} else {
+			ref.EndChapter, ref.EndVerse = ref.Chapter, ref.Verse
+		}
+
+		// whatever follows the next comma continues from where this item ended
+		chapter, inVerses = ref.EndChapter, ref.Verse != 0
+
+		if err := ref.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, errs
+}
+
+// parsePoint parses "a" or "a:b"
+func parsePoint(s string) (a int, b int, colon bool, ok bool) {
+	match := pointPattern.FindStringSubmatch(s)
+	if match == nil {
+		return 0, 0, false, false
+	}
+	a, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		b, _ = strconv.Atoi(match[2])
+		return a, b, true, true
+	}
+	return a, 0, false, true
+}
@@ -0,0 +1,141 @@
+package scripture
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ref is a passage within a single book, from Chapter:Verse to EndChapter:EndVerse inclusive.
+// Verse and EndVerse are both 0 when the passage is whole chapters.
+type Ref struct {
+	Book       string `json:"book"` // USFM book code
+	Chapter    int    `json:"chapter"`
+	Verse      int    `json:"verse"`
+	EndChapter int    `json:"end_chapter"`
+	EndVerse   int    `json:"end_verse"`
+}
+
+// WholeChapters reports whether the passage is made of whole chapters rather than verses
+func (r Ref) WholeChapters() bool {
+	return r.Verse == 0 && r.EndVerse == 0
+}
+
+// Overlaps reports whether the two passages share any verses
+func (r Ref) Overlaps(other Ref) bool {
+	if r.Book != other.Book {
+		return false
+	}
+	start, end := r.bounds()
+	otherStart, otherEnd := other.bounds()
+	return start <= otherEnd && otherStart <= end
+}
+
+// bounds orders the start and end of the passage as chapter*1000+verse, whole chapters
+// running from verse 1 to the end of the chapter
+func (r Ref) bounds() (start int, end int) {
+	startVerse, endVerse := r.Verse, r.EndVerse
+	if r.WholeChapters() {
+		startVerse, endVerse = 1, 999
+	}
+	return r.Chapter*1000 + startVerse, r.EndChapter*1000 + endVerse
+}
+
+// Validate checks the book exists and the passage is within it and runs forwards
+func (r Ref) Validate() error {
+	book, ok := BookByID(r.Book)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownBook, r.Book)
+	}
+
+	if r.Chapter < 1 || r.Chapter > book.Chapters() {
+		return fmt.Errorf("%w: %s has %s, not %d", ErrOutOfRange, book.Name, count(book.Chapters(), "chapter"), r.Chapter)
+	}
+	if r.EndChapter < 1 || r.EndChapter > book.Chapters() {
+		return fmt.Errorf("%w: %s has %s, not %d", ErrOutOfRange, book.Name, count(book.Chapters(), "chapter"), r.EndChapter)
+	}
+	if (r.Verse == 0) != (r.EndVerse == 0) {
+		return fmt.Errorf("%w: a passage needs both a start and end verse or neither", ErrMalformed)
+	}
+	if r.Verse != 0 {
+		if r.Verse < 0 || r.Verse > book.VersesIn(r.Chapter) {
+			return fmt.Errorf("%w: %s %d has %d verses, not %d", ErrOutOfRange, book.Abbrev, r.Chapter, book.VersesIn(r.Chapter), r.Verse)
+		}
+		if r.EndVerse < 0 || r.EndVerse > book.VersesIn(r.EndChapter) {
+			return fmt.Errorf("%w: %s %d has %d verses, not %d", ErrOutOfRange, book.Abbrev, r.EndChapter, book.VersesIn(r.EndChapter), r.EndVerse)
+		}
+	}
+
+	if r.EndChapter < r.Chapter || (r.EndChapter == r.Chapter && r.EndVerse < r.Verse) {
+		return ErrBackwards
+	}
+	return nil
+}
+
+// String formats the passage canonically, e.g. "Matt 5:4-9", "Gen 1:1-2:3", "Ps 23" or "Jude 3".
+// Books with a single chapter leave out the chapter number.
+func (r Ref) String() string {
+	book, ok := BookByID(r.Book)
+	if !ok {
+		return fmt.Sprintf("%s %s", r.Book, r.passage(false))
+	}
+	if book.Chapters() == 1 {
+		if r.WholeChapters() {
+			return book.Abbrev
+		}
+		return fmt.Sprintf("%s %s", book.Abbrev, r.passage(true))
+	}
+	return fmt.Sprintf("%s %s", book.Abbrev, r.passage(false))
+}
+
+// passage formats the chapter and verse part of the reference
+func (r Ref) passage(versesOnly bool) string {
+	switch {
+	case versesOnly && r.Verse == r.EndVerse:
+		return fmt.Sprint(r.Verse)
+	case versesOnly:
+		return fmt.Sprintf("%d-%d", r.Verse, r.EndVerse)
+	case r.WholeChapters() && r.Chapter == r.EndChapter:
+		return fmt.Sprint(r.Chapter)
+	case r.WholeChapters():
+		return fmt.Sprintf("%d-%d", r.Chapter, r.EndChapter)
+	case r.Chapter != r.EndChapter:
+		return fmt.Sprintf("%d:%d-%d:%d", r.Chapter, r.Verse, r.EndChapter, r.EndVerse)
+	case r.Verse == r.EndVerse:
+		return fmt.Sprintf("%d:%d", r.Chapter, r.Verse)
+	default:
+		return fmt.Sprintf("%d:%d-%d", r.Chapter, r.Verse, r.EndVerse)
+	}
+}
+
+// Format formats references canonically as a pipe separated list, the way a note's relevant_verses are stored
+func Format(refs []Ref) string {
+	parts := make([]string, len(refs))
+	for i, ref := range refs {
+		parts[i] = ref.String()
+	}
+	return strings.Join(parts, "|")
+}
+
+// count formats a number of things, e.g. "1 chapter" or "3 chapters"
+func count(n int, thing string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, thing)
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// FormatPassage formats references canonically as a single passage, e.g. "Matt 5:3-12; Matt 6:1"
+func FormatPassage(refs []Ref) string {
+	parts := make([]string, len(refs))
+	for i, ref := range refs {
+		parts[i] = ref.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Normalize parses free text references and formats them canonically. References that can't be
+// understood are left out, and reported in the error.
+func Normalize(text string) (string, error) {
+	refs, err := Parse(text)
+	return Format(refs), err
+}
@@ -0,0 +1,219 @@
+package speakers
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// titles are dropped when comparing names, "Pastor Mike" and "Mike" are the same person
+var titles = map[string]string{
+	"pastor":     "Pastor",
+	"pr":         "Pastor",
+	"ps":         "Pastor",
+	"rev":        "Reverend",
+	"reverend":   "Reverend",
+	"dr":         "Dr.",
+	"doctor":     "Dr.",
+	"elder":      "Elder",
+	"bishop":     "Bishop",
+	"father":     "Father",
+	"fr":         "Father",
+	"deacon":     "Deacon",
+	"minister":   "Minister",
+	"evangelist": "Evangelist",
+	"apostle":    "Apostle",
+	"brother":    "Brother",
+	"bro":        "Brother",
+	"sister":     "Sister",
+	"sis":        "Sister",
+	"mr":         "",
+	"mrs":        "",
+	"ms":         "",
+	"miss":       "",
+}
+
+// suffixes are dropped when comparing names
+var suffixes = map[string]bool{"jr": true, "sr": true, "ii": true, "iii": true, "iv": true}
+
+// nicknames maps short forms of first names to the full name they're compared as
+var nicknames = map[string]string{
+	"mike": "michael", "mick": "michael", "dave": "david", "jim": "james", "jimmy": "james",
+	"bob": "robert", "rob": "robert", "bobby": "robert", "bill": "william", "will": "william",
+	"billy": "william", "tom": "thomas", "tommy": "thomas", "steve": "steven", "stephen": "steven",
+	"chris": "christopher", "dan": "daniel", "danny": "daniel", "joe": "joseph", "joey": "joseph",
+	"matt": "matthew", "andy": "andrew", "drew": "andrew", "tony": "anthony", "nick": "nicholas",
+	"sam": "samuel", "ben": "benjamin", "josh": "joshua", "jon": "jonathan", "johnny": "john",
+	"jack": "john", "rick": "richard", "dick": "richard", "rich": "richard", "ed": "edward",
+	"eddie": "edward", "greg": "gregory", "jeff": "jeffrey", "ken": "kenneth", "larry": "lawrence",
+	"pete": "peter", "phil": "philip", "ron": "ronald", "tim": "timothy", "zach": "zachary",
+	"liz": "elizabeth", "beth": "elizabeth", "kate": "katherine", "katie": "katherine",
+	"sue": "susan", "jen": "jennifer", "jenny": "jennifer", "becky": "rebecca", "pat": "patricia",
+	"deb": "deborah", "debbie": "deborah", "cathy": "catherine", "abby": "abigail",
+}
+
+// Name is a speaker's name broken down for comparison
+type Name struct {
+	Display string   // The name without titles, as it should be shown
+	Title   string   // The title that was dropped, e.g. Pastor, used as the speaker's role
+	tokens  []string // Lowercase words of the name, nicknames expanded
+}
+
+// ParseName splits the name from any titles and suffixes, e.g. "Pastor Mike Smith Jr." is Mike Smith, a Pastor
+func ParseName(name string) Name {
+	parsed := Name{}
+	display := []string{}
+	for _, word := range strings.Fields(name) {
+		key := strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }))
+		if key == "" {
+			continue
+		}
+		if title, ok := titles[key]; ok && len(parsed.tokens) == 0 {
+			if parsed.Title == "" {
+				parsed.Title = title
+			}
+			continue
+		}
+		if suffixes[key] && len(parsed.tokens) > 0 {
+			display = append(display, word)
+			continue
+		}
+
+		display = append(display, word)
+		for _, part := range strings.FieldsFunc(key, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+			if full, ok := nicknames[part]; ok {
+				part = full
+			}
+			parsed.tokens = append(parsed.tokens, part)
+		}
+	}
+
+	parsed.Display = strings.Join(display, " ")
+	if parsed.Display == "" && strings.TrimSpace(name) != "" {
+		// only a title, like "Pastor", is still somebody
+		parsed.Display = strings.TrimSpace(name)
+		parsed.tokens = []string{strings.ToLower(parsed.Display)}
+		parsed.Title = ""
+	}
+	return parsed
+}
+
+// Empty reports whether there's no name at all
+func (n Name) Empty() bool {
+	return len(n.tokens) == 0
+}
+
+func (n Name) key() string {
+	return strings.Join(n.tokens, " ")
+}
+
+// Match finds which of the known names is the same person as the name, -1 if none. A name matches when
+//   - it's the same once titles, punctuation and nicknames are set aside ("Rev. Dave Jones" and "David Jones")
+//   - it only differs by a typo or two ("Mike Smtih")
+//   - it's a first or last name alone that only one of the known names has ("Pastor Mike" and "Mike Smith")
+func Match(name Name, known []Name) int {
+	if name.Empty() {
+		return -1
+	}
+
+	for i, other := range known {
+		if !other.Empty() && name.key() == other.key() {
+			return i
+		}
+	}
+
+	for i, other := range known {
+		if other.Empty() || len(name.tokens) != len(other.tokens) {
+			continue
+		}
+		if distance(name.key(), other.key()) <= maxTypos(name.key()) {
+			return i
+		}
+	}
+
+	// a lone first or last name is only a match when it can't be anyone else
+	partial := func(short Name, full Name) bool {
+		if len(short.tokens) != 1 || len(full.tokens) < 2 {
+			return false
+		}
+		return short.tokens[0] == full.tokens[0] || short.tokens[0] == full.tokens[len(full.tokens)-1]
+	}
+	found := -1
+	for i, other := range known {
+		if partial(name, other) || partial(other, name) {
+			if found >= 0 {
+				return -1
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+// maxTypos is how many edits two names of the length can differ by and still be the same
+func maxTypos(key string) int {
+	switch n := len([]rune(key)); {
+	case n >= 12:
+		return 2
+	case n >= 6:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// distance is the Levenshtein distance between two strings
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// Group clusters names that belong to the same person, fullest names first so a lone first name joins the
+// person it belongs to. The first name in each group is the most complete one.
+func Group(names []string) [][]string {
+	parsed := make([]Name, len(names))
+	order := make([]int, len(names))
+	for i, name := range names {
+		parsed[i] = ParseName(name)
+		order[i] = i
+	}
+	// the names with the most words first, then alphabetically
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := parsed[order[i]], parsed[order[j]]
+		if len(a.tokens) != len(b.tokens) {
+			return len(a.tokens) > len(b.tokens)
+		}
+		return a.Display < b.Display
+	})
+
+	groups := [][]string{}
+	heads := []Name{}
+	for _, i := range order {
+		if parsed[i].Empty() {
+			continue
+		}
+		if g := Match(parsed[i], heads); g >= 0 {
+			groups[g] = append(groups[g], names[i])
+			continue
+		}
+		groups = append(groups, []string{names[i]})
+		heads = append(heads, parsed[i])
+	}
+	return groups
+}
@@ -0,0 +1,99 @@
+package speakers
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Collection holds the people who give sermons
+const Collection = "speakers"
+
+// Resolve finds the speaker the name refers to, creating one when it's somebody new.
+// It returns nil when the name is blank.
+func Resolve(app core.App, name string) (*core.Record, error) {
+	parsed := ParseName(name)
+	if parsed.Empty() {
+		return nil, nil
+	}
+
+	records, err := app.FindAllRecords(Collection)
+	if err != nil {
+		return nil, err
+	}
+	known := make([]Name, len(records))
+	for i, record := range records {
+		known[i] = ParseName(record.GetString("name"))
+	}
+	if i := Match(parsed, known); i >= 0 {
+		return records[i], nil
+	}
+
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		return nil, err
+	}
+	speaker := core.NewRecord(collection)
+	speaker.Set("name", parsed.Display)
+	speaker.Set("role", parsed.Title)
+	if err := app.Save(speaker); err != nil {
+		return nil, err
+	}
+	return speaker, nil
+}
+
+// Backfill links sermons that only have a speaker's name to a speaker, grouping the different ways the
+// same person was written so they end up as one speaker named by the most complete of them
+func Backfill(app core.App) error {
+	names := []string{}
+	err := app.DB().
+		Select("speaker").
+		Distinct(true).
+		From("sermons").
+		Where(dbx.NewExp("speaker != '' AND (speaker_id = '' OR speaker_id IS NULL)")).
+		Column(&names)
+	if err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, group := range Group(names) {
+			speaker, err := Resolve(txApp, group[0])
+			if err != nil {
+				return err
+			}
+			// "Pastor Mike" tells us what "Mike Smith" does
+			for _, name := range group {
+				if title := ParseName(name).Title; title != "" && speaker.GetString("role") == "" {
+					speaker.Set("role", title)
+					if err := txApp.Save(speaker); err != nil {
+						return err
+					}
+				}
+			}
+
+			for _, name := range group {
+				sermons, err := txApp.FindAllRecords("sermons", dbx.HashExp{"speaker": name, "speaker_id": ""})
+				if err != nil {
+					return err
+				}
+				for _, sermon := range sermons {
+					Link(sermon, speaker)
+					if err := txApp.Save(sermon); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Link sets the sermon's speaker, keeping its speaker name in step
+func Link(sermon *core.Record, speaker *core.Record) {
+	if speaker == nil {
+		sermon.Set("speaker_id", "")
+		return
+	}
+	sermon.Set("speaker_id", speaker.Id)
+	sermon.Set("speaker", speaker.GetString("name"))
+}
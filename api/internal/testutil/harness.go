@@ -0,0 +1,224 @@
+// Package testutil boots a throwaway PocketBase app with the real migrations so the
+// analysis pipeline can be exercised end to end without calling a model.
+package testutil
+
+import (
+	"api/internal/ai"
+	"api/internal/hooks"
+	"api/internal/jobs"
+	"api/internal/models"
+	"api/internal/routes"
+	_ "api/migrations"
+	"io"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// Harness is a migrated PocketBase app in a temp pb_data dir wired to a FakeAnalyzer.
+//
+// It registers the fake as the "fake" provider and sets AI_PROVIDER for the test, along with
+// EMBEDDING_PROVIDER for the fake embedder, so it can't be used from parallel tests.
+type Harness struct {
+	tb       testing.TB
+	App      *tests.TestApp
+	Analyzer *ai.FakeAnalyzer
+}
+
+func NewHarness(tb testing.TB, responses ...ai.FakeResponse) *Harness {
+	tb.Helper()
+
+	app, err := tests.NewTestApp(tb.TempDir())
+	if err != nil {
+		tb.Fatalf("failed to create test app: %v", err)
+	}
+	tb.Cleanup(app.Cleanup)
+	hooks.ConfigureHooks(app)
+	routes.ConfigureRoutes(app)
+
+	analyzer := ai.NewFakeAnalyzer(responses...)
+	ai.RegisterProvider("fake", analyzer.Provider())
+	tb.Cleanup(func() {
+		ai.RegisterProvider("fake", ai.DefaultFakeProvider)
+	})
+	tb.Setenv("AI_PROVIDER", "fake")
+	tb.Setenv("EMBEDDING_PROVIDER", "fake")
+
+	return &Harness{
+		tb:       tb,
+		App:      app,
+		Analyzer: analyzer,
+	}
+}
+
+// CreateSermon inserts a sermon in the 'created' status along with its analysis job
+func (h *Harness) CreateSermon(title string, audioURL string) (sermonId string, jobId string) {
+	h.tb.Helper()
+
+	sermon := h.newRecord("sermons")
+	sermon.Set("title", title)
+	sermon.Set("status", models.SermonStatusCreated)
+	h.save(sermon)
+
+	job := h.newRecord("analysis_jobs")
+	job.Set("sermon_id", sermon.Id)
+	job.Set("audio_url", audioURL)
+	h.save(job)
+
+	return sermon.Id, job.Id
+}
+
+// RunJob runs a single tick of the analysis job
+func (h *Harness) RunJob() {
+	jobs.SermonAnalysisJob(h.App)
+}
+
+// RunJobsConcurrently runs n ticks of the analysis job at the same time, like overlapping cron runs
+func (h *Harness) RunJobsConcurrently(n int) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jobs.SermonAnalysisJob(h.App)
+		}()
+	}
+	wg.Wait()
+}
+
+// CreateUser inserts a user with the given role and returns an auth token for them
+func (h *Harness) CreateUser(email string, role string) (token string) {
+	h.tb.Helper()
+
+	user := h.newRecord("users")
+	user.SetEmail(email)
+	user.SetPassword("password1234")
+	h.save(user)
+
+	// the create hook always starts users off as viewers
+	user.Set("role", role)
+	h.save(user)
+
+	token, err := user.NewAuthToken()
+	if err != nil {
+		h.tb.Fatalf("failed to create auth token: %v", err)
+	}
+	return token
+}
+
+// Request sends a request through the app's router, including the custom routes
+func (h *Harness) Request(method string, url string, token string, body io.Reader) *httptest.ResponseRecorder {
+	h.tb.Helper()
+
+	router, err := apis.NewRouter(h.App)
+	if err != nil {
+		h.tb.Fatalf("failed to create router: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	serveEvent := &core.ServeEvent{App: h.App, Router: router}
+	err = h.App.OnServe().Trigger(serveEvent, func(e *core.ServeEvent) error {
+		mux, err := e.Router.BuildMux()
+		if err != nil {
+			return err
+		}
+
+		req := httptest.NewRequest(method, url, body)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+		mux.ServeHTTP(recorder, req)
+		return nil
+	})
+	if err != nil {
+		h.tb.Fatalf("failed to serve request: %v", err)
+	}
+
+	return recorder
+}
+
+// StartPool starts a worker pool against the harness app, stopping it when the test ends
+func (h *Harness) StartPool(cfg jobs.PoolConfig) *jobs.Pool {
+	pool := jobs.NewPool(h.App, cfg)
+	pool.Start()
+	h.tb.Cleanup(pool.Stop)
+	return pool
+}
+
+// Status returns the current status of a sermon
+func (h *Harness) Status(sermonId string) string {
+	h.tb.Helper()
+
+	sermon, err := h.App.FindRecordById("sermons", sermonId)
+	if err != nil {
+		h.tb.Fatalf("failed to find sermon %s: %v", sermonId, err)
+	}
+	return sermon.GetString("status")
+}
+
+// RequireStatus fails the test if the sermon isn't in the wanted status
+func (h *Harness) RequireStatus(sermonId string, want string) {
+	h.tb.Helper()
+
+	if got := h.Status(sermonId); got != want {
+		h.tb.Fatalf("expected sermon %s to have status %q, got %q", sermonId, want, got)
+	}
+}
+
+// Details returns the stored notes for a sermon in order
+func (h *Harness) Details(sermonId string) []models.SermonDetail {
+	h.tb.Helper()
+
+	details := []models.SermonDetail{}
+	err := h.App.DB().
+		Select("*").
+		From("sermon_details").
+		Where(dbx.HashExp{"sermon_id": sermonId}).
+		OrderBy("order").
+		All(&details)
+	if err != nil {
+		h.tb.Fatalf("failed to load sermon details: %v", err)
+	}
+	return details
+}
+
+// Questions returns the stored discussion questions for a sermon in order
+func (h *Harness) Questions(sermonId string) []models.SermonQuestion {
+	h.tb.Helper()
+
+	questions := []models.SermonQuestion{}
+	err := h.App.DB().
+		Select("*").
+		From("sermon_questions").
+		Where(dbx.HashExp{"sermon_id": sermonId}).
+		OrderBy("order").
+		All(&questions)
+	if err != nil {
+		h.tb.Fatalf("failed to load sermon questions: %v", err)
+	}
+	return questions
+}
+
+func (h *Harness) newRecord(collection string) *core.Record {
+	h.tb.Helper()
+
+	c, err := h.App.FindCollectionByNameOrId(collection)
+	if err != nil {
+		h.tb.Fatalf("failed to find collection %s: %v", collection, err)
+	}
+	return core.NewRecord(c)
+}
+
+func (h *Harness) save(record *core.Record) {
+	h.tb.Helper()
+
+	if err := h.App.Save(record); err != nil {
+		h.tb.Fatalf("failed to save %s record: %v", record.Collection().Name, err)
+	}
+}
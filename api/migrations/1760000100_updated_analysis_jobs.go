@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_1880364383")
+		if err != nil {
+			return err
+		}
+
+		// update collection data
+		if err := json.Unmarshal([]byte(`{
+			"indexes": [
+				"CREATE INDEX ` + "`" + `idx_Zixb9PLh9J` + "`" + ` ON ` + "`" + `analysis_jobs` + "`" + ` (` + "`" + `sermon_id` + "`" + `)",
+				"CREATE INDEX ` + "`" + `idx_q3VbTn8sLk` + "`" + ` ON ` + "`" + `analysis_jobs` + "`" + ` (` + "`" + `status` + "`" + `)"
+			]
+		}`), &collection); err != nil {
+			return err
+		}
+
+		// add field
+		if err := collection.Fields.AddMarshaledJSONAt(3, []byte(`{
+			"hidden": false,
+			"id": "select2063623452",
+			"maxSelect": 1,
+			"name": "status",
+			"presentable": false,
+			"required": false,
+			"system": false,
+			"type": "select",
+			"values": [
+				"queued",
+				"running",
+				"complete",
+				"failed"
+			]
+		}`)); err != nil {
+			return err
+		}
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// existing jobs take their status from the sermon they belong to
+		_, err = app.DB().NewQuery(`
+			UPDATE analysis_jobs SET status = CASE (SELECT status FROM sermons WHERE sermons.id = analysis_jobs.sermon_id)
+				WHEN 'created' THEN 'queued'
+				WHEN 'pending' THEN 'running'
+				WHEN 'error' THEN 'failed'
+				ELSE 'complete'
+			END
+		`).Execute()
+		return err
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_1880364383")
+		if err != nil {
+			return err
+		}
+
+		// update collection data
+		if err := json.Unmarshal([]byte(`{
+			"indexes": [
+				"CREATE INDEX ` + "`" + `idx_Zixb9PLh9J` + "`" + ` ON ` + "`" + `analysis_jobs` + "`" + ` (` + "`" + `sermon_id` + "`" + `)"
+			]
+		}`), &collection); err != nil {
+			return err
+		}
+
+		// remove field
+		collection.Fields.RemoveById("select2063623452")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_2988540424")
+		if err != nil {
+			return err
+		}
+
+		// add field
+		if err := collection.Fields.AddMarshaledJSONAt(6, []byte(`{
+			"hidden": false,
+			"id": "file3274582604",
+			"maxSelect": 1,
+			"maxSize": 524288000,
+			"mimeTypes": [
+				"audio/mpeg",
+				"audio/mp4",
+				"audio/x-m4a",
+				"audio/aac",
+				"audio/wav",
+				"audio/x-wav",
+				"audio/ogg",
+				"audio/flac",
+				"audio/x-flac",
+				"audio/webm"
+			],
+			"name": "audio_file",
+			"presentable": false,
+			"protected": true,
+			"required": false,
+			"system": false,
+			"thumbs": null,
+			"type": "file"
+		}`)); err != nil {
+			return err
+		}
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_2988540424")
+		if err != nil {
+			return err
+		}
+
+		// remove field
+		collection.Fields.RemoveById("file3274582604")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,128 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jsonData := `{
+			"createRule": null,
+			"deleteRule": null,
+			"fields": [
+				{
+					"autogeneratePattern": "[a-z0-9]{15}",
+					"hidden": false,
+					"id": "text3208210256",
+					"max": 15,
+					"min": 15,
+					"name": "id",
+					"pattern": "^[a-z0-9]+$",
+					"presentable": false,
+					"primaryKey": true,
+					"required": true,
+					"system": true,
+					"type": "text"
+				},
+				{
+					"cascadeDelete": false,
+					"collectionId": "pbc_2988540424",
+					"hidden": false,
+					"id": "relation556459113",
+					"maxSelect": 1,
+					"minSelect": 0,
+					"name": "sermon_id",
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "relation"
+				},
+				{
+					"hidden": false,
+					"id": "number2239936856",
+					"max": null,
+					"min": 0,
+					"name": "start_ms",
+					"onlyInt": true,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"hidden": false,
+					"id": "number84069075",
+					"max": null,
+					"min": 0,
+					"name": "end_ms",
+					"onlyInt": true,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"autogeneratePattern": "",
+					"hidden": false,
+					"id": "text999008199",
+					"max": 0,
+					"min": 0,
+					"name": "text",
+					"pattern": "",
+					"presentable": false,
+					"primaryKey": false,
+					"required": false,
+					"system": false,
+					"type": "text"
+				},
+				{
+					"hidden": false,
+					"id": "autodate2990389176",
+					"name": "created",
+					"onCreate": true,
+					"onUpdate": false,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				},
+				{
+					"hidden": false,
+					"id": "autodate3332085495",
+					"name": "updated",
+					"onCreate": true,
+					"onUpdate": true,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				}
+			],
+			"id": "pbc_1570390828",
+			"indexes": [
+				"CREATE INDEX ` + "`" + `idx_T7cWq2LmRx` + "`" + ` ON ` + "`" + `sermon_transcripts` + "`" + ` (` + "`" + `sermon_id` + "`" + `, ` + "`" + `start_ms` + "`" + `)"
+			],
+			"listRule": "@request.auth.role = 'admin' || sermon_id.status = 'complete'",
+			"name": "sermon_transcripts",
+			"system": false,
+			"type": "base",
+			"updateRule": "@request.auth.role = 'admin'",
+			"viewRule": "@request.auth.role = 'admin' || sermon_id.status = 'complete'"
+		}`
+
+		collection := &core.Collection{}
+		if err := json.Unmarshal([]byte(jsonData), &collection); err != nil {
+			return err
+		}
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_1570390828")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,194 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jsonData := `{
+			"createRule": null,
+			"deleteRule": null,
+			"fields": [
+				{
+					"autogeneratePattern": "[a-z0-9]{15}",
+					"hidden": false,
+					"id": "text3208210256",
+					"max": 15,
+					"min": 15,
+					"name": "id",
+					"pattern": "^[a-z0-9]+$",
+					"presentable": false,
+					"primaryKey": true,
+					"required": true,
+					"system": true,
+					"type": "text"
+				},
+				{
+					"cascadeDelete": false,
+					"collectionId": "pbc_2988540424",
+					"hidden": false,
+					"id": "relation556459113",
+					"maxSelect": 1,
+					"minSelect": 0,
+					"name": "sermon_id",
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "relation"
+				},
+				{
+					"cascadeDelete": true,
+					"collectionId": "pbc_4155639682",
+					"hidden": false,
+					"id": "relation3637511099",
+					"maxSelect": 1,
+					"minSelect": 0,
+					"name": "detail_id",
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "relation"
+				},
+				{
+					"hidden": false,
+					"id": "select1002749145",
+					"maxSelect": 1,
+					"name": "kind",
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "select",
+					"values": [
+						"key",
+						"relevant"
+					]
+				},
+				{
+					"autogeneratePattern": "",
+					"hidden": false,
+					"id": "text3420824369",
+					"max": 0,
+					"min": 0,
+					"name": "book",
+					"pattern": "",
+					"presentable": false,
+					"primaryKey": false,
+					"required": false,
+					"system": false,
+					"type": "text"
+				},
+				{
+					"hidden": false,
+					"id": "number4186027310",
+					"max": null,
+					"min": 0,
+					"name": "chapter",
+					"onlyInt": true,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"hidden": false,
+					"id": "number3539461791",
+					"max": null,
+					"min": 0,
+					"name": "verse",
+					"onlyInt": true,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"hidden": false,
+					"id": "number1817109703",
+					"max": null,
+					"min": 0,
+					"name": "end_chapter",
+					"onlyInt": true,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"hidden": false,
+					"id": "number2741543322",
+					"max": null,
+					"min": 0,
+					"name": "end_verse",
+					"onlyInt": true,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"autogeneratePattern": "",
+					"hidden": false,
+					"id": "text2929936659",
+					"max": 0,
+					"min": 0,
+					"name": "reference",
+					"pattern": "",
+					"presentable": false,
+					"primaryKey": false,
+					"required": false,
+					"system": false,
+					"type": "text"
+				},
+				{
+					"hidden": false,
+					"id": "autodate2990389176",
+					"name": "created",
+					"onCreate": true,
+					"onUpdate": false,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				},
+				{
+					"hidden": false,
+					"id": "autodate3332085495",
+					"name": "updated",
+					"onCreate": true,
+					"onUpdate": true,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				}
+			],
+			"id": "pbc_4293068797",
+			"indexes": [
+				"CREATE INDEX ` + "`" + `idx_Vr4nQk8ZpL` + "`" + ` ON ` + "`" + `sermon_verse_refs` + "`" + ` (` + "`" + `sermon_id` + "`" + `)",
+				"CREATE INDEX ` + "`" + `idx_h2WcXe9TbM` + "`" + ` ON ` + "`" + `sermon_verse_refs` + "`" + ` (` + "`" + `book` + "`" + `, ` + "`" + `chapter` + "`" + `)"
+			],
+			"listRule": "@request.auth.role = 'admin' || sermon_id.status = 'complete'",
+			"name": "sermon_verse_refs",
+			"system": false,
+			"type": "base",
+			"updateRule": null,
+			"viewRule": "@request.auth.role = 'admin' || sermon_id.status = 'complete'"
+		}`
+
+		collection := &core.Collection{}
+		if err := json.Unmarshal([]byte(jsonData), &collection); err != nil {
+			return err
+		}
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_4293068797")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"api/internal/models"
+	"api/internal/scripture"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_4293068797")
+		if err != nil {
+			return err
+		}
+
+		// index the verses of notes written before references were stored, skipping any that don't parse
+		details, err := app.FindAllRecords("sermon_details")
+		if err != nil {
+			return err
+		}
+		for _, detail := range details {
+			indexed, err := app.CountRecords(collection, dbx.HashExp{"detail_id": detail.Id})
+			if err != nil {
+				return err
+			}
+			if indexed > 0 {
+				continue
+			}
+
+			for kind, field := range map[string]string{models.VerseRefKey: "key_verse", models.VerseRefRelevant: "relevant_verses"} {
+				refs, _ := scripture.Parse(detail.GetString(field))
+				for _, ref := range refs {
+					record := core.NewRecord(collection)
+					record.Set("sermon_id", detail.GetString("sermon_id"))
+					record.Set("detail_id", detail.Id)
+					record.Set("kind", kind)
+					record.Set("book", ref.Book)
+					record.Set("chapter", ref.Chapter)
+					record.Set("verse", ref.Verse)
+					record.Set("end_chapter", ref.EndChapter)
+					record.Set("end_verse", ref.EndVerse)
+					record.Set("reference", ref.String())
+					if err := app.Save(record); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		// the references are indistinguishable from ones stored since, so they're left in place
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"api/internal/fulltext"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		_, err := app.DB().NewQuery(`
+			CREATE VIRTUAL TABLE IF NOT EXISTS sermon_search USING fts5(
+				sermon_id UNINDEXED,
+				source UNINDEXED,
+				record_id UNINDEXED,
+				start_ms UNINDEXED,
+				title,
+				body,
+				tokenize = 'porter unicode61 remove_diacritics 2'
+			)`).Execute()
+		if err != nil {
+			return err
+		}
+
+		// index everything written before the search table existed
+		return fulltext.Reindex(app)
+	}, func(app core.App) error {
+		_, err := app.DB().NewQuery("DROP TABLE IF EXISTS sermon_search").Execute()
+		return err
+	})
+}
@@ -0,0 +1,157 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jsonData := `{
+			"createRule": null,
+			"deleteRule": null,
+			"fields": [
+				{
+					"autogeneratePattern": "[a-z0-9]{15}",
+					"hidden": false,
+					"id": "text3208210256",
+					"max": 15,
+					"min": 15,
+					"name": "id",
+					"pattern": "^[a-z0-9]+$",
+					"presentable": false,
+					"primaryKey": true,
+					"required": true,
+					"system": true,
+					"type": "text"
+				},
+				{
+					"cascadeDelete": true,
+					"collectionId": "pbc_2988540424",
+					"hidden": false,
+					"id": "relation556459113",
+					"maxSelect": 1,
+					"minSelect": 0,
+					"name": "sermon_id",
+					"presentable": false,
+					"required": true,
+					"system": false,
+					"type": "relation"
+				},
+				{
+					"cascadeDelete": true,
+					"collectionId": "pbc_2988540424",
+					"hidden": false,
+					"id": "relation1096990721",
+					"maxSelect": 1,
+					"minSelect": 0,
+					"name": "related_id",
+					"presentable": false,
+					"required": true,
+					"system": false,
+					"type": "relation"
+				},
+				{
+					"hidden": false,
+					"id": "number848901969",
+					"max": null,
+					"min": 0,
+					"name": "score",
+					"onlyInt": false,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"hidden": false,
+					"id": "json3996334591",
+					"maxSize": 0,
+					"name": "shared_refs",
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "json"
+				},
+				{
+					"hidden": false,
+					"id": "json4061021197",
+					"maxSize": 0,
+					"name": "shared_topics",
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "json"
+				},
+				{
+					"hidden": false,
+					"id": "bool3780735693",
+					"name": "same_speaker",
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "bool"
+				},
+				{
+					"hidden": false,
+					"id": "number1680515392",
+					"max": null,
+					"min": 0,
+					"name": "similarity",
+					"onlyInt": false,
+					"presentable": false,
+					"required": false,
+					"system": false,
+					"type": "number"
+				},
+				{
+					"hidden": false,
+					"id": "autodate2990389176",
+					"name": "created",
+					"onCreate": true,
+					"onUpdate": false,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				},
+				{
+					"hidden": false,
+					"id": "autodate3332085495",
+					"name": "updated",
+					"onCreate": true,
+					"onUpdate": true,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				}
+			],
+			"id": "pbc_3854793880",
+			"indexes": [
+				"CREATE UNIQUE INDEX ` + "`" + `idx_ql8J1tS2CT` + "`" + ` ON ` + "`" + `sermon_related` + "`" + ` (` + "`" + `sermon_id` + "`" + `, ` + "`" + `related_id` + "`" + `)",
+				"CREATE INDEX ` + "`" + `idx_7PBvrYDfzK` + "`" + ` ON ` + "`" + `sermon_related` + "`" + ` (` + "`" + `related_id` + "`" + `)"
+			],
+			"listRule": null,
+			"name": "sermon_related",
+			"system": false,
+			"type": "base",
+			"updateRule": null,
+			"viewRule": null
+		}`
+
+		collection := &core.Collection{}
+		if err := json.Unmarshal([]byte(jsonData), &collection); err != nil {
+			return err
+		}
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_3854793880")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
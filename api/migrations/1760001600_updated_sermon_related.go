@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// related sermons compare speakers, so they're worked out once speakers exist (see 1760001900)
+		return nil
+	}, func(app core.App) error {
+		return nil
+	})
+}
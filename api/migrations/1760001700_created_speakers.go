@@ -0,0 +1,141 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jsonData := `{
+			"createRule": "@request.auth.role = 'admin'",
+			"deleteRule": "@request.auth.role = 'admin'",
+			"fields": [
+				{
+					"autogeneratePattern": "[a-z0-9]{15}",
+					"hidden": false,
+					"id": "text3208210256",
+					"max": 15,
+					"min": 15,
+					"name": "id",
+					"pattern": "^[a-z0-9]+$",
+					"presentable": false,
+					"primaryKey": true,
+					"required": true,
+					"system": true,
+					"type": "text"
+				},
+				{
+					"autogeneratePattern": "",
+					"hidden": false,
+					"id": "text1579384326",
+					"max": 0,
+					"min": 0,
+					"name": "name",
+					"pattern": "",
+					"presentable": true,
+					"primaryKey": false,
+					"required": true,
+					"system": false,
+					"type": "text"
+				},
+				{
+					"autogeneratePattern": "",
+					"hidden": false,
+					"id": "text3709889147",
+					"max": 0,
+					"min": 0,
+					"name": "bio",
+					"pattern": "",
+					"presentable": false,
+					"primaryKey": false,
+					"required": false,
+					"system": false,
+					"type": "text"
+				},
+				{
+					"hidden": false,
+					"id": "file347571224",
+					"maxSelect": 1,
+					"maxSize": 0,
+					"mimeTypes": [
+						"image/jpeg",
+						"image/png",
+						"image/svg+xml",
+						"image/gif",
+						"image/webp"
+					],
+					"name": "photo",
+					"presentable": false,
+					"protected": false,
+					"required": false,
+					"system": false,
+					"thumbs": [
+						"100x100"
+					],
+					"type": "file"
+				},
+				{
+					"autogeneratePattern": "",
+					"hidden": false,
+					"id": "text1466534506",
+					"max": 0,
+					"min": 0,
+					"name": "role",
+					"pattern": "",
+					"presentable": false,
+					"primaryKey": false,
+					"required": false,
+					"system": false,
+					"type": "text"
+				},
+				{
+					"hidden": false,
+					"id": "autodate2990389176",
+					"name": "created",
+					"onCreate": true,
+					"onUpdate": false,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				},
+				{
+					"hidden": false,
+					"id": "autodate3332085495",
+					"name": "updated",
+					"onCreate": true,
+					"onUpdate": true,
+					"presentable": false,
+					"system": false,
+					"type": "autodate"
+				}
+			],
+			"id": "pbc_1636713223",
+			"indexes": [
+				"CREATE UNIQUE INDEX ` + "`" + `idx_zOePJVTECw` + "`" + ` ON ` + "`" + `speakers` + "`" + ` (` + "`" + `name` + "`" + `)"
+			],
+			"listRule": "",
+			"name": "speakers",
+			"system": false,
+			"type": "base",
+			"updateRule": "@request.auth.role = 'admin'",
+			"viewRule": ""
+		}`
+
+		collection := &core.Collection{}
+		if err := json.Unmarshal([]byte(jsonData), &collection); err != nil {
+			return err
+		}
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_1636713223")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
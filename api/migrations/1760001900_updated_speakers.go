@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"api/internal/embeddings"
+	"api/internal/related"
+	"api/internal/speakers"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// turn the names typed in before speakers were kept into speakers, one per person
+		if err := speakers.Backfill(app); err != nil {
+			return err
+		}
+
+		// then work out the related sermons of sermons completed before they were kept, along with the
+		// summary vectors of the configured embedder where they've already been computed
+		model := ""
+		if embedder, err := embeddings.NewEmbedder(); err == nil {
+			model = embedder.Model()
+		}
+		return related.ComputeAll(app, model)
+	}, func(app core.App) error {
+		// the sermons keep their speaker names, the speakers go with the collection
+		return nil
+	})
+}
@@ -4,9 +4,11 @@ import { utils } from "../lib/utils";
 interface SermonCardProps {
   sermon: RecordModel;
   showStatus?: boolean;
+  // HTML excerpt of a search match, with the matching words in <mark> tags
+  snippet?: string;
 }
 
-export function SermonCard({ sermon, showStatus = false }: SermonCardProps) {
+export function SermonCard({ sermon, showStatus = false, snippet }: SermonCardProps) {
   const formatDate = (dateString?: string) => {
     if (!dateString) return "No date";
     try {
@@ -69,6 +71,13 @@ export function SermonCard({ sermon, showStatus = false }: SermonCardProps) {
           {truncateText(sermon.summary)}
         </p>
       )}
+
+      {snippet && (
+        <p
+          class="mt-3 text-surface-300 text-sm italic leading-relaxed [&_mark]:bg-primary-700 [&_mark]:text-surface-50"
+          dangerouslySetInnerHTML={{ __html: snippet }}
+        />
+      )}
     </a>
   );
 }
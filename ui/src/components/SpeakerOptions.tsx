@@ -0,0 +1,26 @@
+import { RecordModel } from "pocketbase";
+import { useEffect, useState } from "preact/hooks";
+import { getApiClient } from "../lib/api";
+
+// Suggests the known speakers for a speaker input, pointed at with list={id}. Names that don't match
+// a speaker create a new one when the sermon is saved.
+export function SpeakerOptions({ id }: { id: string }) {
+  const [speakers, setSpeakers] = useState<RecordModel[]>([]);
+  const client = getApiClient();
+
+  useEffect(() => {
+    client
+      .collection("speakers")
+      .getFullList({ sort: "name" })
+      .then(setSpeakers)
+      .catch(() => setSpeakers([]));
+  }, []);
+
+  return (
+    <datalist id={id}>
+      {speakers.map((speaker) => (
+        <option key={speaker.id} value={speaker.name} />
+      ))}
+    </datalist>
+  );
+}
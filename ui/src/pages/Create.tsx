@@ -3,6 +3,7 @@ import { getApiClient, getErrors } from "../lib/api";
 import { ClientResponseError } from "pocketbase";
 import { Alert } from "../components/Alert";
 import { Button } from "../components/Button";
+import { SpeakerOptions } from "../components/SpeakerOptions";
 
 export function Create() {
   const client = getApiClient();
@@ -10,6 +11,9 @@ export function Create() {
   const [date, setDate] = useState("");
   const [speaker, setSpeaker] = useState("");
   const [audioUrl, setAudioUrl] = useState("");
+  const [audioFile, setAudioFile] = useState<File | null>(null);
+  const [startOffset, setStartOffset] = useState("");
+  const [endOffset, setEndOffset] = useState("");
   const [error, setError] = useState<string | null>(null);
   const [loading, setLoading] = useState(false);
 
@@ -42,10 +46,13 @@ export function Create() {
         date_given: utcDate,
         status: "created",
         speaker: speaker,
+        ...(audioFile ? { audio_file: audioFile } : {}),
       });
       const job = await client.collection("analysis_jobs").create({
         sermon_id: sermon.id,
-        audio_url: audioUrl,
+        audio_url: audioFile ? "" : audioUrl,
+        start_offset: startOffset,
+        end_offset: endOffset,
       });
 
       window.location.href = '/';
@@ -128,6 +135,7 @@ export function Create() {
                 id="speaker"
                 name="speaker"
                 type="text"
+                list="speaker-options"
                 disabled={loading}
                 value={speaker}
                 onInput={(e) =>
@@ -136,6 +144,7 @@ export function Create() {
                 class="appearance-none block w-full px-3 py-3 border border-surface-300 dark:border-surface-600 rounded-lg placeholder-surface-400 dark:placeholder-surface-500 bg-white dark:bg-surface-800 text-background-900 dark:text-background-100 focus:outline-none focus:ring-2 focus:ring-primary-500 focus:border-primary-500 transition-colors duration-200"
                 placeholder="Enter speaker name"
               />
+              <SpeakerOptions id="speaker-options" />
             </div>
           </div>
 
@@ -151,8 +160,8 @@ export function Create() {
                 id="audioUrl"
                 name="audioUrl"
                 type="url"
-                required
-                disabled={loading}
+                required={!audioFile}
+                disabled={loading || !!audioFile}
                 value={audioUrl}
                 onInput={(e) =>
                   setAudioUrl((e.target as HTMLInputElement).value)
@@ -163,6 +172,77 @@ export function Create() {
             </div>
           </div>
 
+          <div>
+            <label
+              htmlFor="audioFile"
+              class="block text-sm font-medium text-background-700 dark:text-background-300"
+            >
+              Or Upload Audio
+            </label>
+            <div class="mt-1">
+              <input
+                id="audioFile"
+                name="audioFile"
+                type="file"
+                accept="audio/*"
+                disabled={loading}
+                onChange={(e) =>
+                  setAudioFile((e.target as HTMLInputElement).files?.[0] ?? null)
+                }
+                class="block w-full text-sm text-background-700 dark:text-background-300 file:mr-4 file:py-2 file:px-4 file:rounded-lg file:border-0 file:bg-primary-600 file:text-white hover:file:bg-primary-700"
+              />
+            </div>
+          </div>
+
+          <div class="flex gap-4">
+            <div class="flex-1">
+              <label
+                htmlFor="startOffset"
+                class="block text-sm font-medium text-background-700 dark:text-background-300"
+              >
+                Sermon Starts At
+              </label>
+              <div class="mt-1">
+                <input
+                  id="startOffset"
+                  name="startOffset"
+                  type="text"
+                  pattern="^(\d+:){0,2}\d+(\.\d+)?$"
+                  disabled={loading}
+                  value={startOffset}
+                  onInput={(e) =>
+                    setStartOffset((e.target as HTMLInputElement).value)
+                  }
+                  class="appearance-none block w-full px-3 py-3 border border-surface-300 dark:border-surface-600 rounded-lg placeholder-surface-400 dark:placeholder-surface-500 bg-white dark:bg-surface-800 text-background-900 dark:text-background-100 focus:outline-none focus:ring-2 focus:ring-primary-500 focus:border-primary-500 transition-colors duration-200"
+                  placeholder="41:20"
+                />
+              </div>
+            </div>
+            <div class="flex-1">
+              <label
+                htmlFor="endOffset"
+                class="block text-sm font-medium text-background-700 dark:text-background-300"
+              >
+                Sermon Ends At
+              </label>
+              <div class="mt-1">
+                <input
+                  id="endOffset"
+                  name="endOffset"
+                  type="text"
+                  pattern="^(\d+:){0,2}\d+(\.\d+)?$"
+                  disabled={loading}
+                  value={endOffset}
+                  onInput={(e) =>
+                    setEndOffset((e.target as HTMLInputElement).value)
+                  }
+                  class="appearance-none block w-full px-3 py-3 border border-surface-300 dark:border-surface-600 rounded-lg placeholder-surface-400 dark:placeholder-surface-500 bg-white dark:bg-surface-800 text-background-900 dark:text-background-100 focus:outline-none focus:ring-2 focus:ring-primary-500 focus:border-primary-500 transition-colors duration-200"
+                  placeholder="1:18:05"
+                />
+              </div>
+            </div>
+          </div>
+
           <div class="flex gap-4">
             <Button type="submit" loading={loading} className="flex-1">
               Create Sermon
@@ -5,6 +5,7 @@ import { getApiClient, getErrors } from "../lib/api";
 import { Alert } from "../components/Alert";
 import { Button } from "../components/Button";
 import { BackToTop } from "../components/BackToTop";
+import { SpeakerOptions } from "../components/SpeakerOptions";
 import { ClientResponseError } from "pocketbase";
 
 export function Edit() {
@@ -237,6 +238,7 @@ export function Edit() {
               </label>
               <input
                 type="text"
+                list="speaker-options"
                 value={speaker}
                 onInput={(e) =>
                   setSpeaker((e.target as HTMLInputElement).value)
@@ -244,6 +246,7 @@ export function Edit() {
                 class="w-full px-3 py-2 border border-surface-600 rounded-lg bg-surface-700 text-surface-100 focus:outline-none focus:ring-2 focus:ring-primary-500"
                 disabled={saving}
               />
+              <SpeakerOptions id="speaker-options" />
             </div>
 
             <div>
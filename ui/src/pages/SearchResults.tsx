@@ -6,8 +6,30 @@ import { LoadingSpinner } from "../components/LoadingSpinner";
 import { Button } from "../components/Button";
 import { RecordModel } from "pocketbase";
 
+interface KeywordMatch {
+  source: "sermon" | "note" | "question" | "transcript";
+  record_id: string;
+  start_ms: number;
+  snippet: string;
+  matches: number;
+}
+
+interface SearchItem {
+  sermon: RecordModel;
+  score: number;
+  keyword: KeywordMatch | null;
+}
+
+interface SearchResult {
+  page: number;
+  perPage: number;
+  totalItems: number;
+  totalPages: number;
+  items: SearchItem[];
+}
+
 export function SearchResults() {
-  const [sermons, setSermons] = useState<RecordModel[]>([]);
+  const [results, setResults] = useState<SearchItem[]>([]);
   const [loading, setLoading] = useState(true);
   const [loadingMore, setLoadingMore] = useState(false);
   const [error, setError] = useState<string | null>(null);
@@ -48,15 +70,14 @@ export function SearchResults() {
       }
       setError(null);
 
-      const result = await client.collection("sermons").getList(page, ITEMS_PER_PAGE, {
-        filter: `title ~ "${searchQuery}" || summary ~ "${searchQuery}"`,
-        sort: "-date_given,-created",
+      const result = await client.send<SearchResult>("/api/search/hybrid", {
+        query: { q: searchQuery, page, perPage: ITEMS_PER_PAGE },
       });
 
       if (page === 1) {
-        setSermons(result.items);
+        setResults(result.items);
       } else {
-        setSermons(prev => [...prev, ...result.items]);
+        setResults(prev => [...prev, ...result.items]);
       }
       
       setCurrentPage(page);
@@ -100,7 +121,7 @@ export function SearchResults() {
       );
     }
 
-    if (sermons.length === 0) {
+    if (results.length === 0) {
       return (
         <div class="bg-surface-800 border border-surface-700 rounded-lg p-8 text-center">
           <p class="text-surface-300 text-lg">
@@ -121,8 +142,13 @@ export function SearchResults() {
     return (
       <>
         <div class="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
-          {sermons.map((sermon) => (
-            <SermonCard key={sermon.id} sermon={sermon} showStatus={isAdmin} />
+          {results.map((result) => (
+            <SermonCard
+              key={result.sermon.id}
+              sermon={result.sermon}
+              showStatus={isAdmin}
+              snippet={result.keyword?.source === "sermon" ? undefined : result.keyword?.snippet}
+            />
           ))}
         </div>
         
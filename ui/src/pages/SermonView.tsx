@@ -7,11 +7,13 @@ import { BackToTop } from "../components/BackToTop";
 import { AreYouSure } from "../components/AreYouSure";
 import { Dropdown } from "../components/Dropdown";
 import { utils } from "../lib/utils";
+import { SermonCard } from "../components/SermonCard";
 
 export function SermonView() {
   const [sermon, setSermon] = useState<RecordModel | null>(null);
   const [details, setDetails] = useState<RecordModel[]>([]);
   const [questions, setQuestions] = useState<RecordModel[]>([]);
+  const [warnings, setWarnings] = useState<RecordModel[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
 
@@ -49,6 +51,17 @@ export function SermonView() {
           sort: "order",
         });
       setQuestions(questionsResult);
+
+      // Fetch problems with the analysis for admins to review
+      if (isAdmin) {
+        const warningsResult = await client
+          .collection("sermon_warnings")
+          .getFullList({
+            filter: `sermon_id="${sermonId}"`,
+            sort: "created",
+          });
+        setWarnings(warningsResult);
+      }
     } catch (err) {
       console.error("Failed to fetch sermon:", err);
       setError(
@@ -67,6 +80,15 @@ export function SermonView() {
     window.location.href = "/";
   };
 
+  const dismissWarnings = async () => {
+    await Promise.all(
+      warnings.map((warning) =>
+        client.collection("sermon_warnings").delete(warning.id)
+      )
+    );
+    setWarnings([]);
+  };
+
   useEffect(() => {
     if (!sermonId) {
       setError("No sermon ID provided");
@@ -148,12 +170,28 @@ export function SermonView() {
           )}
         </div>
 
+        {isAdmin && warnings.length > 0 && (
+          <Alert
+            type="warning"
+            title={`${warnings.length} verse reference${
+              warnings.length === 1 ? " was" : "s were"
+            } dropped from the notes`}
+            message={warnings
+              .map((warning) => `${warning.reference} (${warning.message})`)
+              .join("; ")}
+            dismissible
+            onDismiss={dismissWarnings}
+          />
+        )}
+
         <SermonSummary sermon={sermon} isAdmin={isAdmin} />
 
         {details.length > 0 && <SermonNotes details={details} />}
 
         {questions.length > 0 && <SermonQuestions questions={questions} />}
 
+        {sermon.status === "complete" && <RelatedSermons sermonId={sermon.id} />}
+
         {details.length === 0 &&
           questions.length === 0 &&
           sermon.status === "complete" && (
@@ -277,6 +315,7 @@ function SermonNotes({ details }: { details: RecordModel[] }) {
                 >
                   {detail.key_verse}
                 </a>
+                <VerseText reference={detail.key_verse} />
               </div>
             )}
 
@@ -330,3 +369,74 @@ function SermonQuestions({ questions }: { questions: RecordModel[] }) {
     </div>
   );
 }
+
+interface RelatedSermon {
+  sermon: RecordModel;
+  score: number;
+  shared_refs: string[];
+  shared_topics: string[];
+  same_speaker: boolean;
+}
+
+// Points readers at other sermons on the same passages or themes, or nothing if there aren't any
+function RelatedSermons({ sermonId }: { sermonId: string }) {
+  const [related, setRelated] = useState<RelatedSermon[]>([]);
+  const client = getApiClient();
+
+  useEffect(() => {
+    client
+      .send<{ items: RelatedSermon[] }>(`/api/sermons/${sermonId}/related`, {})
+      .then((result) => setRelated(result.items.slice(0, 4)))
+      .catch(() => setRelated([]));
+  }, [sermonId]);
+
+  if (related.length === 0) return null;
+
+  return (
+    <div class="bg-surface-800 border border-surface-700 rounded-lg p-3 md:p-6">
+      <h2 class="text-2xl font-bold text-surface-50 mb-6">Related Sermons</h2>
+      <div class="grid grid-cols-1 md:grid-cols-2 gap-4">
+        {related.map((item) => (
+          <div key={item.sermon.id}>
+            <SermonCard sermon={item.sermon} />
+            {(item.shared_refs.length > 0 || item.shared_topics.length > 0) && (
+              <p class="mt-2 text-surface-400 text-sm">
+                {[...item.shared_refs, ...item.shared_topics].join(" · ")}
+              </p>
+            )}
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+}
+
+interface ScripturePassage {
+  reference: string;
+  text: string;
+}
+
+// Shows the text of a passage from the bible bundled with the api, or nothing if it isn't available
+function VerseText({ reference }: { reference: string }) {
+  const [passages, setPassages] = useState<ScripturePassage[]>([]);
+  const client = getApiClient();
+
+  useEffect(() => {
+    client
+      .send<{ passages: ScripturePassage[] }>("/api/scripture/text", {
+        query: { ref: reference },
+      })
+      .then((result) => setPassages(result.passages))
+      .catch(() => setPassages([]));
+  }, [reference]);
+
+  if (passages.length === 0) return null;
+
+  return (
+    <blockquote class="mt-1 border-l-2 border-surface-500 pl-3 italic text-surface-300">
+      {passages.map((passage) => (
+        <p key={passage.reference}>{passage.text}</p>
+      ))}
+    </blockquote>
+  );
+}